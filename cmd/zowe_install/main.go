@@ -1,22 +1,441 @@
 package main
 
 import (
+	"encoding/json"
+	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
+	"text/tabwriter"
 
+	humanize "github.com/dustin/go-humanize"
 	"github.com/lchudinov/zowe_installer/installer"
+	"github.com/pkg/errors"
 )
 
+// Exit codes of zowe_install, the stage that failed is told by a separate code.
+const (
+	exitOK        = 0
+	exitError     = 1
+	exitPrepare   = 2
+	exitDownload  = 3
+	exitVerify    = 4
+	exitExtract   = 5
+	exitInstall   = 6
+	exitConfigure = 7
+)
+
+var stageExitCodes = map[installer.Stage]int{
+	installer.StagePrepare:   exitPrepare,
+	installer.StageDownload:  exitDownload,
+	installer.StageVerify:    exitVerify,
+	installer.StageExtract:   exitExtract,
+	installer.StageInstall:   exitInstall,
+	installer.StageConfigure: exitConfigure,
+}
+
+const exitCodesUsage = `
+Exit codes:
+  0  success
+  1  usage or unexpected error
+  2  preparing the target directories failed
+  3  downloading the PAX failed
+  4  checksum or signature verification failed
+  5  extracting the PAX failed
+  6  zowe-install.sh failed
+  7  zowe-configure-instance.sh failed
+`
+
 func main() {
-	if len(os.Args) != 2 {
-		fmt.Printf("Usage: %s <Zowe PAX URL>\n", filepath.Base(os.Args[0]))
-		os.Exit(1)
-	}
-	paxURL := os.Args[1]
-	installer := installer.New()
-	if err := installer.Install(paxURL); err != nil {
-		log.Fatalf("failed to install Zowe pax %s: %v", paxURL, err)
+	// a first argument naming a command runs it, a PAX file of the same name
+	// is installed with ./<file> or after --
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "list":
+			listVersions(os.Args[2:])
+			return
+		case "switch":
+			switchVersion(os.Args[2:])
+			return
+		case "prune":
+			pruneVersions(os.Args[2:])
+			return
+		case "cache":
+			manageCache(os.Args[2:])
+			return
+		}
+	}
+	// doctor takes the same options as an installation
+	args := os.Args[1:]
+	doctor := len(args) > 0 && args[0] == "doctor"
+	if doctor {
+		args = args[1:]
+	}
+	var spec *installer.Spec
+	if file := specFile(args); file != "" {
+		var err error
+		if spec, err = installer.LoadSpec(file); err != nil {
+			log.Fatal(err)
+		}
+	}
+	authUsage := fmt.Sprintf("user for basic auth of downloads from the PAX host, the password is read from $%s; $%s sets a bearer token instead", installer.EnvPassword, installer.EnvToken)
+	zi := installer.New()
+	zi.Credentials = installer.CredentialsFromEnv()
+	if spec != nil {
+		zi.ApplySpec(spec)
+	}
+	// the spec values are the defaults of the flags, so that flags override them
+	flag.String("config", "", "YAML or JSON install spec, options given as flags override it")
+	printConfig := flag.Bool("print-config", false, "print the effective configuration merged from the spec and the flags")
+	flag.StringVar(&zi.Checksum, "checksum", zi.Checksum, "expected PAX digest, sha256:<hex> or sha512:<hex>")
+	flag.BoolVar(&zi.VerifySidecar, "verify-sidecar", zi.VerifySidecar, "verify the PAX against the .sha512 file published next to it")
+	flag.StringVar(&zi.Keyring, "keyring", zi.Keyring, "armored OpenPGP public keyring to verify the PAX signature with")
+	flag.StringVar(&zi.SignatureURL, "signature-url", zi.SignatureURL, "URL of the detached PAX signature (default <PAX URL>.asc)")
+	flag.DurationVar(&zi.ConnectTimeout, "connect-timeout", zi.ConnectTimeout, "timeout for connecting to the download server")
+	flag.DurationVar(&zi.ReadTimeout, "read-timeout", zi.ReadTimeout, "timeout for a download that stops receiving data")
+	flag.DurationVar(&zi.Timeout, "timeout", zi.Timeout, "overall download timeout including retries, 0 for none")
+	flag.StringVar(&zi.HTTP.Proxy, "proxy", "", "proxy URL for downloads (default from HTTP_PROXY and HTTPS_PROXY)")
+	flag.StringVar(&zi.HTTP.NoProxy, "no-proxy", os.Getenv("NO_PROXY"), "comma separated hosts, domains and CIDR ranges reached without the proxy")
+	flag.StringVar(&zi.HTTP.CABundle, "ca-bundle", "", "PEM file with extra CA certificates to trust")
+	flag.StringVar(&zi.HTTP.ClientCert, "client-cert", "", "PEM client certificate for downloads")
+	flag.StringVar(&zi.HTTP.ClientKey, "client-key", "", "PEM key of the client certificate (default the certificate file)")
+	flag.BoolVar(&zi.HTTP.Insecure, "insecure", false, "don't verify server certificates, unsafe")
+	flag.StringVar(&zi.Credentials.Username, "auth-user", zi.Credentials.Username, authUsage)
+	flag.StringVar(&zi.CredentialsFile, "credentials-file", "", "JSON file with download credentials per host")
+	flag.StringVar(&zi.NetrcFile, "netrc", installer.DefaultNetrcFile(), ".netrc file with download credentials per host, empty for none")
+	flag.IntVar(&zi.Retries, "retries", zi.Retries, "number of retries after a transient download error")
+	flag.DurationVar(&zi.RetryDelay, "retry-delay", zi.RetryDelay, "delay before the first retry, doubled for every next one")
+	var mirrors, extensions stringList
+	flag.Var(&mirrors, "mirror", "mirror to try when the download fails, repeat for more; a URL ending with / is a directory holding the PAX")
+	flag.StringVar(&zi.MirrorsFile, "mirrors-file", zi.MirrorsFile, "file listing mirrors, one URL per line")
+	flag.StringVar(&zi.CacheDir, "cache-dir", installer.DefaultCacheDir(), "directory caching downloaded PAX files for all users of the host")
+	noCache := flag.Bool("no-cache", false, "don't use the cache of downloaded PAX files")
+	flag.StringVar(&zi.PaxName, "name", zi.PaxName, "PAX file name, required when reading the PAX from stdin")
+	flag.StringVar(&zi.WorkDir, "work-dir", zi.WorkDir, "directory to download and extract the PAX in (default $HOME/<PAX name>)")
+	flag.StringVar(&zi.RootDir, "root-dir", zi.RootDir, "ROOT_DIR to install Zowe into (default <work dir>/root)")
+	flag.StringVar(&zi.VersionsDir, "versions-dir", zi.VersionsDir, "install into <dir>/zowe-<version> and point <dir>/current at it")
+	flag.StringVar(&zi.InstanceDir, "instance-dir", zi.InstanceDir, "INSTANCE_DIR to configure (default <work dir>/instance)")
+	flag.StringVar(&zi.LogDir, "log-dir", zi.LogDir, "directory for the zowe-install.sh log")
+	flag.StringVar(&zi.User, "user", zi.User, "user name or id passed to zowe-install.sh (default the current user)")
+	flag.StringVar(&zi.Group, "group", zi.Group, "group name or id passed to zowe-configure-instance.sh (default the primary group of the user)")
+	flag.Var(&extensions, "extension", "component archive or directory to install into the instance, repeat for more")
+	flag.Var((*envFlag)(&zi.InstanceEnv), "instance-env", "NAME=VALUE to set in instance.env, repeat for more")
+	flag.BoolVar(&zi.Force, "force", false, "delete the contents of existing non-empty target directories")
+	flag.BoolVar(&zi.Backup, "backup", false, "move existing non-empty target directories to timestamped backups")
+	flag.BoolVar(&zi.Resume, "resume", false, "skip the stages completed by an earlier run of the same installation")
+	flag.BoolVar(&zi.Rollback, "rollback", false, "undo the steps run so far when a step fails")
+	flag.StringVar(&zi.HooksFile, "hooks", zi.HooksFile, "JSON file listing hook scripts to run before or after the install steps")
+	dryRun := flag.Bool("dry-run", false, "print the install plan and check preconditions without changing anything")
+	format := flag.String("format", "text", "format of the dry run plan, the doctor report and the printed configuration, text or json")
+	progress := flag.String("progress", "terminal", "progress output, terminal, quiet or json")
+	flag.Usage = func() {
+		name := filepath.Base(os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [options] <Zowe PAX URL | file | ->\n", name)
+		fmt.Fprintf(flag.CommandLine.Output(), "       %s -config <spec> [options] [Zowe PAX URL | file | -]\n", name)
+		fmt.Fprintf(flag.CommandLine.Output(), "       %s doctor [options] <Zowe PAX URL | file | ->\n", name)
+		fmt.Fprintf(flag.CommandLine.Output(), "       %s list -versions-dir <dir>\n", name)
+		fmt.Fprintf(flag.CommandLine.Output(), "       %s switch -versions-dir <dir> <version>\n", name)
+		fmt.Fprintf(flag.CommandLine.Output(), "       %s prune -versions-dir <dir> [-keep N] [-keep-days N] [-cache-dir <dir>] [-dry-run]\n", name)
+		fmt.Fprintf(flag.CommandLine.Output(), "       %s cache list|clear [-cache-dir <dir>] [-older-than <duration>]\n", name)
+		fmt.Fprintf(flag.CommandLine.Output(), "A PAX file named like a command is given as ./<file> or after --, as in %s -- list\n", name)
+		flag.PrintDefaults()
+		fmt.Fprint(flag.CommandLine.Output(), exitCodesUsage)
+	}
+	flag.CommandLine.Parse(args)
+	paxURL := flag.Arg(0)
+	if paxURL == "" && spec != nil {
+		paxURL = spec.Source
+	}
+	if flag.NArg() > 1 || paxURL == "" {
+		flag.Usage()
+		os.Exit(exitError)
+	}
+	if *noCache {
+		zi.CacheDir = ""
+	}
+	if len(mirrors) > 0 {
+		zi.Mirrors = mirrors
+	}
+	if len(extensions) > 0 {
+		zi.Extensions = extensions
+	}
+	effective := zi.Spec(paxURL)
+	if err := effective.Validate(); err != nil {
+		log.Fatalf("invalid configuration: %v", err)
+	}
+	if *printConfig {
+		printSpec(effective, *format)
+		return
+	}
+	zi.Progress, zi.Stdout = progressReporter(*progress)
+	if doctor {
+		runDoctor(zi, paxURL, *format)
+		return
+	}
+	if *dryRun {
+		printPlan(zi, paxURL, *format)
+		return
+	}
+	if err := zi.Install(paxURL); err != nil {
+		log.Printf("failed to install Zowe pax %s: %v", installer.RedactURL(paxURL), err)
+		os.Exit(exitCode(err))
+	}
+}
+
+// specFile returns the value of the -config flag, which has to be known before
+// the other flags are defined.
+func specFile(args []string) string {
+	for i, arg := range args {
+		if arg == "--" {
+			return ""
+		}
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		name := strings.TrimLeft(arg, "-")
+		if strings.HasPrefix(name, "config=") {
+			return strings.TrimPrefix(name, "config=")
+		}
+		if name == "config" && i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+func printSpec(spec *installer.Spec, format string) {
+	var err error
+	switch format {
+	case "json":
+		var data []byte
+		if data, err = json.MarshalIndent(spec, "", "  "); err == nil {
+			_, err = fmt.Printf("%s\n", data)
+		}
+	case "text":
+		err = spec.WriteYAML(os.Stdout)
+	default:
+		log.Fatalf("unknown configuration format %s", format)
+	}
+	if err != nil {
+		log.Fatalf("failed to write configuration: %v", err)
+	}
+}
+
+// envFlag is a repeatable NAME=VALUE flag.
+type envFlag map[string]string
+
+func (env *envFlag) String() string {
+	var pairs []string
+	for name, value := range *env {
+		pairs = append(pairs, name+"="+value)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (env *envFlag) Set(value string) error {
+	i := strings.Index(value, "=")
+	if i <= 0 {
+		return errors.Errorf("%s is not NAME=VALUE", value)
+	}
+	// copy the map of the spec rather than changing it
+	merged := map[string]string{value[:i]: value[i+1:]}
+	for name, value := range *env {
+		if _, ok := merged[name]; !ok {
+			merged[name] = value
+		}
+	}
+	*env = merged
+	return nil
+}
+
+// stringList is a flag that can be repeated.
+type stringList []string
+
+func (list *stringList) String() string {
+	return strings.Join(*list, ",")
+}
+
+func (list *stringList) Set(value string) error {
+	*list = append(*list, value)
+	return nil
+}
+
+func exitCode(err error) int {
+	var stageErr *installer.StageError
+	if !errors.As(err, &stageErr) {
+		return exitError
+	}
+	if stageErr.Output != "" {
+		fmt.Fprintf(os.Stderr, "Last output of %s:\n%s\n", stageErr.Command[0], stageErr.Output)
+	}
+	if code, ok := stageExitCodes[stageErr.Stage]; ok {
+		return code
+	}
+	return exitError
+}
+
+// progressReporter returns the reporter for the progress output and where the
+// script output goes. JSON lines take stdout, so scripts write to stderr then.
+func progressReporter(name string) (installer.ProgressReporter, io.Writer) {
+	switch name {
+	case "terminal":
+		return installer.NewTerminalReporter(os.Stdout), os.Stdout
+	case "quiet":
+		return installer.QuietReporter{}, os.Stdout
+	case "json":
+		return installer.NewJSONReporter(os.Stdout), os.Stderr
+	}
+	log.Fatalf("unknown progress output %s", name)
+	return nil, nil
+}
+
+func printPlan(zi *installer.ZoweInstaller, paxURL string, format string) {
+	plan, err := zi.Plan(paxURL)
+	if err != nil {
+		log.Fatalf("failed to plan installation of Zowe pax %s: %v", installer.RedactURL(paxURL), err)
+	}
+	switch format {
+	case "json":
+		if err := plan.WriteJSON(os.Stdout); err != nil {
+			log.Fatalf("failed to write plan: %v", err)
+		}
+	case "text":
+		plan.WriteText(os.Stdout)
+	default:
+		log.Fatalf("unknown plan format %s", format)
+	}
+	if !plan.OK() {
+		os.Exit(exitError)
+	}
+}
+
+func runDoctor(zi *installer.ZoweInstaller, paxURL string, format string) {
+	report, err := zi.Doctor(paxURL)
+	if err != nil {
+		log.Fatalf("failed to check installation of Zowe pax %s: %v", installer.RedactURL(paxURL), err)
+	}
+	switch format {
+	case "json":
+		if err := report.WriteJSON(os.Stdout); err != nil {
+			log.Fatalf("failed to write report: %v", err)
+		}
+	case "text":
+		report.WriteText(os.Stdout)
+	default:
+		log.Fatalf("unknown report format %s", format)
+	}
+	if report.Failed() {
+		os.Exit(exitError)
+	}
+}
+
+// versionsFlags returns the flags of the commands working on a versions
+// directory, with the -versions-dir flag defined.
+func versionsFlags(command string, usage string) (*flag.FlagSet, *string) {
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	versionsDir := flags.String("versions-dir", "", "directory holding the versioned installs")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s %s -versions-dir <dir>%s\n", filepath.Base(os.Args[0]), command, usage)
+		flags.PrintDefaults()
+	}
+	return flags, versionsDir
+}
+
+// parseVersionsFlags parses args and makes sure -versions-dir is set.
+func parseVersionsFlags(flags *flag.FlagSet, versionsDir *string, args []string) []string {
+	flags.Parse(args)
+	if *versionsDir == "" {
+		flags.Usage()
+		os.Exit(exitError)
+	}
+	return flags.Args()
+}
+
+func listVersions(args []string) {
+	flags, versionsDir := versionsFlags("list", "")
+	parseVersionsFlags(flags, versionsDir, args)
+	versions, err := installer.ListVersions(*versionsDir)
+	if err != nil {
+		log.Fatalf("failed to list versions: %v", err)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\tVERSION\tINSTALLED\tDIR")
+	for _, version := range versions {
+		current := ""
+		if version.Current {
+			current = "*"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", current, version.Version, version.Installed.Format("2006-01-02 15:04:05"), version.Dir)
+	}
+	w.Flush()
+}
+
+func switchVersion(args []string) {
+	flags, versionsDir := versionsFlags("switch", " <version>")
+	rest := parseVersionsFlags(flags, versionsDir, args)
+	if len(rest) != 1 {
+		log.Fatalf("expected one version to switch to")
+	}
+	if err := installer.SwitchVersion(*versionsDir, rest[0]); err != nil {
+		log.Fatalf("failed to switch version: %v", err)
+	}
+	log.Printf("Switched %s to %s", filepath.Join(*versionsDir, installer.CurrentLink), rest[0])
+}
+
+func pruneVersions(args []string) {
+	flags, versionsDir := versionsFlags("prune", " [options]")
+	var policy installer.PrunePolicy
+	flags.IntVar(&policy.Keep, "keep", 3, "number of most recently installed versions to keep")
+	flags.IntVar(&policy.KeepDays, "keep-days", 0, "keep versions installed within this many days")
+	flags.StringVar(&policy.CacheDir, "cache-dir", installer.DefaultCacheDir(), "cache to remove the PAX files of the removed versions from, empty to keep them")
+	flags.BoolVar(&policy.DryRun, "dry-run", false, "print what would be removed without removing it")
+	parseVersionsFlags(flags, versionsDir, args)
+	report, err := installer.Prune(*versionsDir, policy)
+	if report != nil {
+		report.WriteText(os.Stdout)
+	}
+	if err != nil {
+		log.Fatalf("failed to prune versions: %v", err)
+	}
+}
+
+func manageCache(args []string) {
+	flags := flag.NewFlagSet("cache", flag.ExitOnError)
+	cacheDir := flags.String("cache-dir", installer.DefaultCacheDir(), "directory caching downloaded PAX files")
+	olderThan := flags.Duration("older-than", 0, "clear only the PAX files not used for this long")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s cache list|clear [options]\n", filepath.Base(os.Args[0]))
+		flags.PrintDefaults()
+	}
+	if len(args) == 0 {
+		flags.Usage()
+		os.Exit(exitError)
+	}
+	flags.Parse(args[1:])
+	cache := installer.NewCache(*cacheDir)
+	switch args[0] {
+	case "list":
+		entries, err := cache.List()
+		if err != nil {
+			log.Fatalf("failed to list cache: %v", err)
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "URL\tSIZE\tLAST USED\tSHA512")
+		for _, entry := range entries {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%.16s\n", entry.URL, humanize.Bytes(uint64(entry.Size)), entry.LastUsed.Format("2006-01-02 15:04:05"), entry.Digests[installer.SHA512])
+		}
+		w.Flush()
+	case "clear":
+		reclaimed, err := cache.Clear(*olderThan)
+		if err != nil {
+			log.Fatalf("failed to clear cache: %v", err)
+		}
+		fmt.Printf("Reclaimed %s\n", humanize.Bytes(uint64(reclaimed)))
+	default:
+		flags.Usage()
+		os.Exit(exitError)
 	}
 }
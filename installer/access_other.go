@@ -0,0 +1,22 @@
+//go:build !windows
+// +build !windows
+
+package installer
+
+import (
+	"os"
+	"syscall"
+)
+
+const accessWrite = 0x2
+
+func checkWritable(dir string) error {
+	return syscall.Access(dir, accessWrite)
+}
+
+// ownedPrivately reports whether fi is owned by the current user and can't be
+// written by anybody else.
+func ownedPrivately(fi os.FileInfo) bool {
+	stat, ok := fi.Sys().(*syscall.Stat_t)
+	return ok && int(stat.Uid) == os.Getuid() && fi.Mode().Perm()&0022 == 0
+}
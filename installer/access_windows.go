@@ -0,0 +1,13 @@
+package installer
+
+import "os"
+
+func checkWritable(dir string) error {
+	return nil
+}
+
+// ownedPrivately reports whether fi is owned by the current user and can't be
+// written by anybody else. ACLs aren't checked, so no file counts as private.
+func ownedPrivately(fi os.FileInfo) bool {
+	return false
+}
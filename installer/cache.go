@@ -0,0 +1,341 @@
+package installer
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"hash"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Cache keeps downloaded PAX files so that installing the same build again, for
+// example by another user of a shared host, doesn't download it again. Files are
+// stored by their SHA-512 digest and indexed by the URL they came from. Without
+// a checksum a PAX is only taken from a cache no other user can write.
+type Cache struct {
+	Dir string
+}
+
+// CacheEntry is a PAX in the cache. Digests maps algorithms to hex digests, the
+// SHA-512 one is always present. ETag and LastModified are the validators the
+// server sent, they tell whether the PAX at URL changed since.
+type CacheEntry struct {
+	URL          string            `json:"url"`
+	Digests      map[string]string `json:"digests"`
+	Size         int64             `json:"size"`
+	ETag         string            `json:"etag,omitempty"`
+	LastModified string            `json:"lastModified,omitempty"`
+	Added        time.Time         `json:"added"`
+	LastUsed     time.Time         `json:"lastUsed"`
+}
+
+func NewCache(dir string) *Cache {
+	return &Cache{Dir: dir}
+}
+
+func (cache *Cache) blobFile(digest string) string {
+	return filepath.Join(cache.Dir, "blobs", SHA512, digest)
+}
+
+func (cache *Cache) indexFile(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(cache.Dir, "index", hex.EncodeToString(sum[:])+".json")
+}
+
+// List returns the cached PAX files, most recently used first.
+func (cache *Cache) List() ([]CacheEntry, error) {
+	files, err := filepath.Glob(filepath.Join(cache.Dir, "index", "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var entries []CacheEntry
+	for _, file := range files {
+		entry, err := readCacheEntry(file)
+		if err != nil {
+			log.Printf("Ignoring unreadable cache entry %s: %v", file, err)
+			continue
+		}
+		entries = append(entries, *entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].LastUsed.After(entries[j].LastUsed)
+	})
+	return entries, nil
+}
+
+func readCacheEntry(file string) (*CacheEntry, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var entry CacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, err
+	}
+	if entry.Digests[SHA512] == "" {
+		return nil, errors.New("missing SHA-512 digest")
+	}
+	return &entry, nil
+}
+
+func (cache *Cache) writeEntry(entry *CacheEntry) error {
+	file := cache.indexFile(entry.URL)
+	if err := os.MkdirAll(filepath.Dir(file), 0775); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := file + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0664); err != nil {
+		return err
+	}
+	return os.Rename(tmp, file)
+}
+
+// lookup finds the PAX downloaded from url or, when checksum is known, any PAX
+// with that digest.
+func (cache *Cache) lookup(url string, checksum *Checksum) *CacheEntry {
+	entries, err := cache.List()
+	if err != nil {
+		return nil
+	}
+	for _, entry := range entries {
+		if checksum != nil {
+			if entry.Digests[checksum.Algorithm] != hex.EncodeToString(checksum.Digest) {
+				continue
+			}
+		} else if entry.URL != url {
+			continue
+		}
+		if _, err := os.Stat(cache.blobFile(entry.Digests[SHA512])); err == nil {
+			entry := entry
+			return &entry
+		}
+	}
+	return nil
+}
+
+// private reports whether only the current user can change the index entry of
+// url. Other users of a shared cache could change the digest and validators of
+// the entry, so it is only trusted without a checksum when it is private. The
+// cached file itself is verified against the digest.
+func (cache *Cache) private(url string) bool {
+	index := cache.indexFile(url)
+	for _, file := range []string{index, filepath.Dir(index), cache.Dir} {
+		fi, err := os.Stat(file)
+		if err != nil || !ownedPrivately(fi) {
+			return false
+		}
+	}
+	return true
+}
+
+// unchanged reports whether header has the validators stored with entry. An
+// entry without validators can't be checked.
+func (entry *CacheEntry) unchanged(header http.Header) bool {
+	switch {
+	case entry.ETag != "":
+		return header.Get("ETag") == entry.ETag
+	case entry.LastModified != "":
+		return header.Get("Last-Modified") == entry.LastModified
+	}
+	return false
+}
+
+// get copies the cached PAX of entry to file and verifies it against its
+// SHA-512 digest and checksum, if any. The cached files are read-only, so they
+// aren't hardlinked.
+func (cache *Cache) get(entry *CacheEntry, file string, checksum *Checksum) error {
+	blob := cache.blobFile(entry.Digests[SHA512])
+	os.Remove(file)
+	if err := copyFile(blob, file); err != nil {
+		return err
+	}
+	hashes := map[string]hash.Hash{SHA512: sha512.New()}
+	if checksum != nil && checksum.Algorithm != SHA512 {
+		hashes[checksum.Algorithm] = checksum.newHash()
+	}
+	var writers []io.Writer
+	for _, h := range hashes {
+		writers = append(writers, h)
+	}
+	if err := hashFile(file, io.MultiWriter(writers...)); err != nil {
+		return err
+	}
+	if digest := hex.EncodeToString(hashes[SHA512].Sum(nil)); digest != entry.Digests[SHA512] {
+		os.Remove(file)
+		os.Remove(blob)
+		return errors.Errorf("cached %s is corrupted", blob)
+	}
+	if checksum != nil {
+		if err := checksum.verify(file, hashes[checksum.Algorithm]); err != nil {
+			os.Remove(file)
+			return err
+		}
+	}
+	entry.LastUsed = time.Now()
+	return cache.writeEntry(entry)
+}
+
+// add stores a copy of file downloaded from url in the cache. Both digests are
+// recorded, so that the file is found with either kind of checksum, together
+// with the validators of the download.
+func (cache *Cache) add(url string, file string, etag string, lastModified string) error {
+	h512 := sha512.New()
+	h256 := sha256.New()
+	if err := hashFile(file, io.MultiWriter(h512, h256)); err != nil {
+		return err
+	}
+	fi, err := os.Stat(file)
+	if err != nil {
+		return err
+	}
+	digest := hex.EncodeToString(h512.Sum(nil))
+	blob := cache.blobFile(digest)
+	if _, err := os.Stat(blob); err != nil {
+		if err := os.MkdirAll(filepath.Dir(blob), 0775); err != nil {
+			return err
+		}
+		tmp := blob + ".tmp"
+		os.Remove(tmp)
+		if err := copyFile(file, tmp); err != nil {
+			return err
+		}
+		os.Chmod(tmp, 0444)
+		if err := os.Rename(tmp, blob); err != nil {
+			return err
+		}
+	}
+	now := time.Now()
+	entry := &CacheEntry{
+		URL:          url,
+		Digests:      map[string]string{SHA512: digest, SHA256: hex.EncodeToString(h256.Sum(nil))},
+		Size:         fi.Size(),
+		ETag:         etag,
+		LastModified: lastModified,
+		Added:        now,
+		LastUsed:     now,
+	}
+	return cache.writeEntry(entry)
+}
+
+// Clear removes the entries not used within olderThan, all of them when it is 0,
+// and the files no entry refers to. It returns the number of bytes reclaimed.
+func (cache *Cache) Clear(olderThan time.Duration) (int64, error) {
+	entries, err := cache.List()
+	if err != nil {
+		return 0, err
+	}
+	used := make(map[string]bool)
+	for _, entry := range entries {
+		if olderThan > 0 && time.Since(entry.LastUsed) < olderThan {
+			used[entry.Digests[SHA512]] = true
+			continue
+		}
+		if err := os.Remove(cache.indexFile(entry.URL)); err != nil && !os.IsNotExist(err) {
+			return 0, errors.Wrapf(err, "failed to remove cache entry of %s", entry.URL)
+		}
+	}
+	blobs, err := filepath.Glob(filepath.Join(cache.Dir, "blobs", SHA512, "*"))
+	if err != nil {
+		return 0, err
+	}
+	var reclaimed int64
+	for _, blob := range blobs {
+		digest := strings.TrimSuffix(filepath.Base(blob), ".tmp")
+		if used[digest] {
+			continue
+		}
+		size := dirSize(blob)
+		if err := os.Remove(blob); err != nil {
+			return reclaimed, errors.Wrapf(err, "failed to remove %s", blob)
+		}
+		reclaimed += size
+	}
+	return reclaimed, nil
+}
+
+func copyFile(from string, to string) error {
+	in, err := os.Open(from)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open %s", from)
+	}
+	defer in.Close()
+	out, err := os.Create(to)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create %s", to)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return errors.Wrapf(err, "failed to copy %s", from)
+	}
+	return out.Close()
+}
+
+// downloadFromCache provides the PAX from the cache. It returns false when the
+// cache has no usable copy.
+func (installer *ZoweInstaller) downloadFromCache() bool {
+	if installer.CacheDir == "" || !isRemote(installer.paxURL) {
+		return false
+	}
+	cache := NewCache(installer.CacheDir)
+	entry := cache.lookup(installer.paxURL, installer.checksum)
+	if entry == nil {
+		return false
+	}
+	if installer.checksum == nil {
+		// found by URL only, the server tells whether the PAX changed since
+		if !cache.private(installer.paxURL) {
+			log.Printf("Ignoring cached PAX: other users can change %s, a checksum is needed to use it", cache.indexFile(installer.paxURL))
+			return false
+		}
+		resp, err := installer.head(installer.paxURL)
+		if err != nil {
+			log.Printf("Ignoring cached PAX: %v", err)
+			return false
+		}
+		if !entry.unchanged(resp.Header) {
+			log.Printf("Ignoring cached PAX: %s may have changed since it was cached", installer.paxURL)
+			return false
+		}
+	}
+	progress := installer.startProgress(StageDownload, 0, entry.Size)
+	if err := cache.get(entry, installer.paxFileName, installer.checksum); err != nil {
+		log.Printf("Ignoring cached PAX: %v", err)
+		return false
+	}
+	progress.add(entry.Size)
+	progress.finish(nil)
+	log.Printf("Using cached PAX %s", cache.blobFile(entry.Digests[SHA512]))
+	if installer.checksum != nil {
+		installer.verified = true
+	}
+	return true
+}
+
+// addToCache stores the downloaded PAX in the cache. Failures are only logged.
+func (installer *ZoweInstaller) addToCache() {
+	if installer.CacheDir == "" || !isRemote(installer.paxURL) {
+		return
+	}
+	var etag, lastModified string
+	if downloaded := installer.downloaded; downloaded != nil && downloaded.URL == installer.paxURL {
+		etag, lastModified = downloaded.ETag, downloaded.LastModified
+	}
+	if err := NewCache(installer.CacheDir).add(installer.paxURL, installer.paxFileName, etag, lastModified); err != nil {
+		log.Printf("failed to cache %s: %v", installer.paxFileName, err)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !windows
+// +build !windows
+
+package installer
+
+// DefaultCacheDir is the cache shared by the users of the host. It has to be
+// created writable for them, for example by a group with the setgid bit set.
+func DefaultCacheDir() string {
+	return "/var/cache/zowe_installer"
+}
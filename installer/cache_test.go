@@ -0,0 +1,136 @@
+package installer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestZoweInstaller_DownloadPaxCache(t *testing.T) {
+	content := strings.Repeat("zowe pax content ", 1000)
+	sum := sha256.Sum256([]byte(content))
+	checksum := "sha256:" + hex.EncodeToString(sum[:])
+	tests := []struct {
+		name         string
+		path         string
+		checksum     string
+		changed      bool
+		shared       bool
+		wantRequests string
+	}{
+		{"same URL", "/zowe.pax", "", false, false, "GET HEAD"},
+		{"changed at the same URL", "/zowe.pax", "", true, false, "GET HEAD GET"},
+		{"same URL in a shared cache", "/zowe.pax", "", false, true, "GET GET"},
+		{"same URL and checksum", "/zowe.pax", checksum, false, false, "GET"},
+		{"same checksum in a shared cache", "/zowe.pax", checksum, false, true, "GET"},
+		{"same checksum from a mirror", "/mirror/zowe.pax", checksum, false, false, "GET"},
+		{"other URL", "/mirror/zowe.pax", "", false, false, "GET GET"},
+		{"other checksum", "/zowe.pax", "sha256:" + strings.Repeat("0", 64), false, false, "GET GET"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if runtime.GOOS == "windows" && tt.checksum == "" && !tt.shared {
+				t.Skip("a cache is never private on Windows")
+			}
+			var requests []string
+			etag := `"v1"`
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests = append(requests, r.Method)
+				w.Header().Set("ETag", etag)
+				if r.Method == http.MethodHead {
+					return
+				}
+				w.Write([]byte(content))
+			}))
+			defer server.Close()
+			dir, err := ioutil.TempDir("", "cache")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			cacheDir := filepath.Join(dir, "cache")
+			for i, path := range []string{"/zowe.pax", tt.path} {
+				installer := New()
+				installer.Progress = QuietReporter{}
+				installer.CacheDir = cacheDir
+				if i == 1 {
+					if tt.shared {
+						os.Chmod(filepath.Join(cacheDir, "index"), 0775)
+					}
+					installer.Checksum = tt.checksum
+					if tt.changed {
+						etag = `"v2"`
+					}
+				}
+				installer.WorkDir = filepath.Join(dir, "work", string(rune('a'+i)))
+				if err := installer.resolve(server.URL + path); err != nil {
+					t.Fatal(err)
+				}
+				os.MkdirAll(installer.dir, 0755)
+				if err := installer.resolveChecksum(); err != nil {
+					t.Fatal(err)
+				}
+				err := installer.DownloadPax()
+				if tt.name == "other checksum" && i == 1 {
+					if _, ok := err.(*ChecksumError); !ok {
+						t.Errorf("DownloadPax() error = %v, want ChecksumError", err)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("DownloadPax() error = %v", err)
+				}
+				data, _ := ioutil.ReadFile(installer.paxFileName)
+				if string(data) != content {
+					t.Errorf("PAX has %d bytes, want %d", len(data), len(content))
+				}
+				if fi, err := os.Stat(installer.paxFileName); err != nil || fi.Mode().Perm()&0200 == 0 {
+					t.Errorf("PAX is read-only or missing: %v", err)
+				}
+			}
+			if got := strings.Join(requests, " "); got != tt.wantRequests {
+				t.Errorf("server got requests %q, want %q", got, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestCache_Clear(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	pax := filepath.Join(dir, "zowe.pax")
+	ioutil.WriteFile(pax, []byte("pax"), 0644)
+	cache := NewCache(filepath.Join(dir, "cache"))
+	for _, url := range []string{"https://example.com/zowe.pax", "https://mirror.example.com/zowe.pax"} {
+		if err := cache.add(url, pax, "", ""); err != nil {
+			t.Fatalf("add() error = %v", err)
+		}
+	}
+	entries, err := cache.List()
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("List() = %v, %v, want 2 entries", entries, err)
+	}
+	if entries[0].Digests[SHA512] != entries[1].Digests[SHA512] {
+		t.Errorf("the same PAX has different digests")
+	}
+	reclaimed, err := cache.Clear(0)
+	if err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	if reclaimed != 3 {
+		t.Errorf("Clear() reclaimed %d, want 3", reclaimed)
+	}
+	if entries, _ := cache.List(); len(entries) != 0 {
+		t.Errorf("List() after Clear() = %v", entries)
+	}
+}
@@ -0,0 +1,15 @@
+package installer
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// DefaultCacheDir is the cache shared by the users of the host, in ProgramData.
+func DefaultCacheDir() string {
+	dir := os.Getenv("ProgramData")
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "zowe_installer")
+}
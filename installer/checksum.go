@@ -0,0 +1,134 @@
+package installer
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	SHA256 = "sha256"
+	SHA512 = "sha512"
+)
+
+// Checksum is an expected digest of a PAX file.
+type Checksum struct {
+	Algorithm string
+	Digest    []byte
+}
+
+// ChecksumError is returned when the digest of a downloaded file doesn't match
+// the expected one.
+type ChecksumError struct {
+	File     string
+	Expected *Checksum
+	Actual   *Checksum
+}
+
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("checksum mismatch for %s: expected %s, got %s", e.File, e.Expected, e.Actual)
+}
+
+// ParseChecksum parses "<algorithm>:<hex digest>". The algorithm may be omitted,
+// then it is guessed from the digest length.
+func ParseChecksum(s string) (*Checksum, error) {
+	algorithm := ""
+	digest := s
+	if i := strings.Index(s, ":"); i >= 0 {
+		algorithm = strings.ToLower(s[:i])
+		digest = s[i+1:]
+	}
+	data, err := hex.DecodeString(strings.TrimSpace(digest))
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid checksum %s", s)
+	}
+	if algorithm == "" {
+		switch len(data) {
+		case sha256.Size:
+			algorithm = SHA256
+		case sha512.Size:
+			algorithm = SHA512
+		}
+	}
+	checksum := &Checksum{Algorithm: algorithm, Digest: data}
+	if checksum.size() == 0 {
+		return nil, errors.Errorf("unsupported checksum algorithm in %s", s)
+	}
+	if len(data) != checksum.size() {
+		return nil, errors.Errorf("invalid %s digest length %d", algorithm, len(data))
+	}
+	return checksum, nil
+}
+
+func (c *Checksum) String() string {
+	return c.Algorithm + ":" + hex.EncodeToString(c.Digest)
+}
+
+func (c *Checksum) size() int {
+	switch c.Algorithm {
+	case SHA256:
+		return sha256.Size
+	case SHA512:
+		return sha512.Size
+	}
+	return 0
+}
+
+func (c *Checksum) newHash() hash.Hash {
+	if c.Algorithm == SHA256 {
+		return sha256.New()
+	}
+	return sha512.New()
+}
+
+func (c *Checksum) verify(file string, h hash.Hash) error {
+	actual := &Checksum{Algorithm: c.Algorithm, Digest: h.Sum(nil)}
+	if !bytes.Equal(actual.Digest, c.Digest) {
+		return &ChecksumError{File: file, Expected: c, Actual: actual}
+	}
+	return nil
+}
+
+// parseSidecar finds the digest in a checksum file. Both the sha512sum format
+// "<hex>  <file>" and the openssl format "SHA512(<file>)= <hex>" are accepted.
+func parseSidecar(r io.Reader, algorithm string) (*Checksum, error) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.LastIndex(line, "= "); i >= 0 {
+			line = line[i+2:]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if checksum, err := ParseChecksum(algorithm + ":" + fields[0]); err == nil {
+			return checksum, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, errors.Errorf("no %s digest found", algorithm)
+}
+
+func (installer *ZoweInstaller) fetchChecksum(location string, algorithm string) (*Checksum, error) {
+	var checksum *Checksum
+	err := installer.readLocation(location, func(r io.Reader) error {
+		var err error
+		checksum, err = parseSidecar(r, algorithm)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read %s", location)
+		}
+		return nil
+	})
+	return checksum, err
+}
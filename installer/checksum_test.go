@@ -0,0 +1,64 @@
+package installer
+
+import (
+	"strings"
+	"testing"
+)
+
+const (
+	testSHA256 = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	testSHA512 = "cf83e1357eefb8bdf1542850d66d8007d620e4050b5715dc83f4a921d36ce9ce47d0d13c5d85f2b0ff8318d2877eec2f63b931bd47417a81a538327af927da3e"
+)
+
+func TestParseChecksum(t *testing.T) {
+	tests := []struct {
+		name    string
+		arg     string
+		want    string
+		wantErr bool
+	}{
+		{"sha256", "sha256:" + testSHA256, "sha256:" + testSHA256, false},
+		{"sha512 upper case", "SHA512:" + strings.ToUpper(testSHA512), "sha512:" + testSHA512, false},
+		{"guess sha256", testSHA256, "sha256:" + testSHA256, false},
+		{"guess sha512", testSHA512, "sha512:" + testSHA512, false},
+		{"wrong length", "sha512:" + testSHA256, "", true},
+		{"unknown algorithm", "md5:d41d8cd98f00b204e9800998ecf8427e", "", true},
+		{"not hex", "sha256:xyz", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseChecksum(tt.arg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseChecksum() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.String() != tt.want {
+				t.Errorf("ParseChecksum() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseSidecar(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{"sha512sum", testSHA512 + "  zowe-1.25.0.pax\n", false},
+		{"openssl", "SHA512(zowe-1.25.0.pax)= " + testSHA512 + "\n", false},
+		{"digest only", "\n" + testSHA512, false},
+		{"empty", "", true},
+		{"garbage", "not found\n", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSidecar(strings.NewReader(tt.content), SHA512)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSidecar() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.String() != "sha512:"+testSHA512 {
+				t.Errorf("parseSidecar() = %s, want sha512:%s", got, testSHA512)
+			}
+		})
+	}
+}
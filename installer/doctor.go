@@ -0,0 +1,326 @@
+package installer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	humanize "github.com/dustin/go-humanize"
+	paxarchive "github.com/lchudinov/zowe_installer/pax"
+	"github.com/pkg/errors"
+)
+
+// CheckStatus is the outcome of a doctor check.
+type CheckStatus string
+
+const (
+	CheckPass CheckStatus = "pass"
+	CheckWarn CheckStatus = "warn"
+	CheckFail CheckStatus = "fail"
+)
+
+// DoctorCheck is the result of a pre-flight check.
+type DoctorCheck struct {
+	Name    string      `json:"name"`
+	Status  CheckStatus `json:"status"`
+	Message string      `json:"message"`
+}
+
+// DoctorReport is the result of Doctor.
+type DoctorReport struct {
+	Checks []DoctorCheck `json:"checks"`
+}
+
+// requiredTools are run by zowe-install.sh and zowe-configure-instance.sh.
+var requiredTools = []string{"pax", "iconv", "sed", "awk"}
+
+// runtimes are the runtimes Zowe needs with the oldest supported major version.
+var runtimes = []struct {
+	name       string
+	homeEnv    string
+	versionArg string
+	minMajor   int
+}{
+	{"java", "JAVA_HOME", "-version", 8},
+	{"node", "NODE_HOME", "--version", 8},
+}
+
+// headroom is the free space wanted on top of what the installation needs,
+// less is a warning.
+const headroom = 1.2
+
+func (report *DoctorReport) add(name string, status CheckStatus, format string, args ...interface{}) {
+	report.Checks = append(report.Checks, DoctorCheck{Name: name, Status: status, Message: fmt.Sprintf(format, args...)})
+}
+
+// Failed reports whether any check failed.
+func (report *DoctorReport) Failed() bool {
+	for _, check := range report.Checks {
+		if check.Status == CheckFail {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteText prints a line per check.
+func (report *DoctorReport) WriteText(w io.Writer) {
+	for _, check := range report.Checks {
+		fmt.Fprintf(w, "[%-4s] %s: %s\n", strings.ToUpper(string(check.Status)), check.Name, check.Message)
+	}
+}
+
+// WriteJSON prints the report as indented JSON.
+func (report *DoctorReport) WriteJSON(w io.Writer) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
+
+// Doctor checks what the installation of the PAX at paxURL and the Zowe scripts
+// need before anything is downloaded or changed: free space, write permission,
+// the user and group, Java and Node, and the tools the scripts run.
+func (installer *ZoweInstaller) Doctor(paxURL string) (*DoctorReport, error) {
+	if err := installer.resolve(paxURL); err != nil {
+		return nil, err
+	}
+	report := &DoctorReport{}
+	installer.doctorSpace(report)
+	installer.doctorTargets(report)
+	installer.doctorUser(report)
+	for _, runtime := range runtimes {
+		doctorRuntime(report, runtime.name, runtime.homeEnv, runtime.versionArg, runtime.minMajor)
+	}
+	for _, tool := range requiredTools {
+		if path, err := exec.LookPath(tool); err != nil {
+			report.add("tool "+tool, CheckFail, "%s not found in PATH", tool)
+		} else {
+			report.add("tool "+tool, CheckPass, "%s", path)
+		}
+	}
+	return report, nil
+}
+
+// paxSizes returns the size of the PAX and of its contents. The contents of a
+// local or already downloaded PAX are summed up, for a remote one they are
+// estimated with the Content-Length.
+func (installer *ZoweInstaller) paxSizes() (size int64, expanded int64, estimated bool, err error) {
+	file := installer.paxFileName
+	if _, err := os.Stat(file); err != nil && !isRemote(installer.paxURL) {
+		if installer.paxURL == Stdin {
+			return 0, 0, false, errors.New("size of a PAX read from stdin is unknown")
+		}
+		if file, err = localPath(installer.paxURL); err != nil {
+			return 0, 0, false, err
+		}
+	}
+	if fi, err := os.Stat(file); err == nil {
+		expanded, err := expandedSize(file)
+		return fi.Size(), expanded, false, err
+	}
+	_, err = installer.fromSources("PAX size", func(source string) error {
+		var err error
+		size, err = installer.headSource(source)
+		return err
+	})
+	if err != nil {
+		return 0, 0, false, err
+	}
+	if size < 0 {
+		return 0, 0, false, errors.New("server didn't send the PAX size")
+	}
+	return size, size, true, nil
+}
+
+// expandedSize sums up the sizes of the files in the PAX.
+func expandedSize(file string) (int64, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to open %s", file)
+	}
+	defer f.Close()
+	var size int64
+	err = paxarchive.Walk(f, func(entry *paxarchive.Entry, r io.Reader) error {
+		if entry.Type == paxarchive.TypeFile {
+			size += entry.Size
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to read %s", file)
+	}
+	return size, nil
+}
+
+// doctorSpace compares the free space with the PAX and the extracted PAX in the
+// work directory and the extracted PAX in the ROOT_DIR.
+func (installer *ZoweInstaller) doctorSpace(report *DoctorReport) {
+	size, expanded, estimated, err := installer.paxSizes()
+	if err != nil {
+		report.add("space", CheckWarn, "can't tell the space needed: %v", err)
+		return
+	}
+	what := "the expanded PAX"
+	if estimated {
+		what = "the expanded PAX, estimated"
+	}
+	workNeeds := expanded
+	workWhat := what
+	if _, err := os.Stat(installer.paxFileName); err != nil {
+		workNeeds += size
+		workWhat = "the PAX and " + what
+	}
+	needs := []struct {
+		dir   string
+		bytes int64
+		what  string
+	}{
+		{installer.dir, workNeeds, workWhat},
+		{installer.rootDir, expanded, what},
+	}
+	for _, need := range needs {
+		name := "space " + need.dir
+		free, err := freeSpace(existingAncestor(need.dir))
+		if err != nil {
+			report.add(name, CheckWarn, "can't tell the free space: %v", err)
+			continue
+		}
+		message := fmt.Sprintf("%s free, %s needed for %s", humanize.Bytes(free), humanize.Bytes(uint64(need.bytes)), need.what)
+		switch {
+		case free < uint64(need.bytes):
+			report.add(name, CheckFail, "%s", message)
+		case float64(free) < float64(need.bytes)*headroom:
+			report.add(name, CheckWarn, "%s, little room to spare", message)
+		default:
+			report.add(name, CheckPass, "%s", message)
+		}
+	}
+}
+
+func (installer *ZoweInstaller) doctorTargets(report *DoctorReport) {
+	dirs := installer.targetDirs()
+	if installer.logDir != "" {
+		dirs = append(dirs, installer.logDir)
+	}
+	for _, dir := range dirs {
+		ancestor := existingAncestor(dir)
+		if err := checkWritable(ancestor); err != nil {
+			report.add("write "+dir, CheckFail, "%s is not writable: %v", ancestor, err)
+		} else {
+			report.add("write "+dir, CheckPass, "%s is writable", ancestor)
+		}
+	}
+}
+
+// doctorUser checks the user passed to zowe-install.sh and that it is a member
+// of the group passed to zowe-configure-instance.sh.
+func (installer *ZoweInstaller) doctorUser(report *DoctorReport) {
+	userName, err := installer.installUser()
+	if err != nil {
+		report.add("user", CheckFail, "%v", err)
+		return
+	}
+	report.add("user", CheckPass, "%s", userName)
+	groupName, err := installer.instanceGroup()
+	if err != nil {
+		report.add("group", CheckFail, "%v", err)
+		return
+	}
+	group, err := lookupGroup(groupName)
+	if err != nil {
+		report.add("group", CheckFail, "%v", err)
+		return
+	}
+	userInfo, err := lookupUser(userName)
+	if err != nil {
+		report.add("group", CheckFail, "%v", err)
+		return
+	}
+	groupIds, err := userInfo.GroupIds()
+	if err != nil {
+		report.add("group", CheckWarn, "can't tell the groups of %s: %v", userName, err)
+		return
+	}
+	for _, gid := range groupIds {
+		if gid == group.Gid {
+			report.add("group", CheckPass, "%s is a member of %s", userName, groupName)
+			return
+		}
+	}
+	report.add("group", CheckFail, "%s is not a member of %s", userName, groupName)
+}
+
+// quotedVersion is the version java prints, like "1.8.0_281" or "17", which
+// doesn't need a minor version.
+var quotedVersion = regexp.MustCompile(`version "((\d+)(?:\.(\d+))?[^"]*)"`)
+
+var versionPattern = regexp.MustCompile(`v?((\d+)\.(\d+)[\w.-]*)`)
+
+// majorVersion returns the major version in the version output of java or
+// node, taking 8 from Java's 1.8.
+func majorVersion(output string) (string, int, error) {
+	match := quotedVersion.FindStringSubmatch(output)
+	if match == nil {
+		match = versionPattern.FindStringSubmatch(output)
+	}
+	if match == nil {
+		return "", 0, errors.New("no version in output")
+	}
+	major, _ := strconv.Atoi(match[2])
+	if major == 1 && match[3] != "" {
+		major, _ = strconv.Atoi(match[3])
+	}
+	return match[1], major, nil
+}
+
+// doctorRuntime finds a runtime in its home directory or in PATH and checks its
+// version. zowe-configure-instance.sh takes the home directory from the
+// environment, so a runtime found only in PATH is a warning.
+func doctorRuntime(report *DoctorReport, name string, homeEnv string, versionArg string, minMajor int) {
+	var path string
+	home := os.Getenv(homeEnv)
+	if home != "" {
+		path = filepath.Join(home, "bin", name)
+		if _, err := exec.LookPath(path); err != nil {
+			report.add(name, CheckFail, "%s=%s has no bin/%s", homeEnv, home, name)
+			return
+		}
+	} else {
+		var err error
+		if path, err = exec.LookPath(name); err != nil {
+			report.add(name, CheckFail, "%s is not set and %s is not in PATH", homeEnv, name)
+			return
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, path, versionArg).CombinedOutput()
+	if err != nil {
+		report.add(name, CheckFail, "failed to run %s %s: %v", path, versionArg, err)
+		return
+	}
+	version, major, err := majorVersion(string(output))
+	if err != nil {
+		report.add(name, CheckWarn, "can't tell the version of %s: %v", path, err)
+		return
+	}
+	switch {
+	case major < minMajor:
+		report.add(name, CheckFail, "%s is version %s, %d or later is required", path, version, minMajor)
+	case home == "":
+		report.add(name, CheckWarn, "%s version %s found in PATH, set %s for zowe-configure-instance.sh", path, version, homeEnv)
+	default:
+		report.add(name, CheckPass, "%s version %s", path, version)
+	}
+}
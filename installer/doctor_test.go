@@ -0,0 +1,116 @@
+package installer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func Test_majorVersion(t *testing.T) {
+	tests := []struct {
+		output      string
+		wantVersion string
+		wantMajor   int
+		wantErr     bool
+	}{
+		{"java version \"1.8.0_281\"\nJava(TM) SE Runtime Environment", "1.8.0_281", 8, false},
+		{"openjdk version \"11.0.12\" 2021-07-20", "11.0.12", 11, false},
+		{"openjdk version \"17\" 2021-09-14\nOpenJDK Runtime Environment (build 17+35-2724)", "17", 17, false},
+		{"v16.13.0\n", "16.13.0", 16, false},
+		{"command not found", "", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.output, func(t *testing.T) {
+			version, major, err := majorVersion(tt.output)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("majorVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if version != tt.wantVersion || major != tt.wantMajor {
+				t.Errorf("majorVersion() = %v, %v, want %v, %v", version, major, tt.wantVersion, tt.wantMajor)
+			}
+		})
+	}
+}
+
+func Test_doctorRuntime(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake runtimes are shell scripts")
+	}
+	home, err := ioutil.TempDir("", "doctor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+	fakeJava := func(name string, version string) string {
+		dir := filepath.Join(home, name)
+		os.MkdirAll(filepath.Join(dir, "bin"), 0755)
+		script := "#!/bin/sh\necho 'java version \"" + version + "\"' >&2\n"
+		ioutil.WriteFile(filepath.Join(dir, "bin", "java"), []byte(script), 0755)
+		return dir
+	}
+	tests := []struct {
+		name     string
+		javaHome string
+		want     CheckStatus
+		wantMsg  string
+	}{
+		{"supported", fakeJava("java8", "1.8.0_281"), CheckPass, "version 1.8.0_281"},
+		{"too old", fakeJava("java7", "1.7.0_80"), CheckFail, "8 or later is required"},
+		{"no java", filepath.Join(home, "missing"), CheckFail, "has no bin/java"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer os.Setenv("JAVA_HOME", os.Getenv("JAVA_HOME"))
+			os.Setenv("JAVA_HOME", tt.javaHome)
+			report := &DoctorReport{}
+			doctorRuntime(report, "java", "JAVA_HOME", "-version", 8)
+			check := report.Checks[0]
+			if check.Status != tt.want || !strings.Contains(check.Message, tt.wantMsg) {
+				t.Errorf("doctorRuntime() = %+v, want %s with %q", check, tt.want, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestZoweInstaller_Doctor(t *testing.T) {
+	home, err := ioutil.TempDir("", "doctor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+	paxFile := filepath.Join(home, "zowe-1.25.0.pax")
+	writePax(t, paxFile, map[string]string{
+		"zowe-1.25.0/manifest.json":           testManifest,
+		"zowe-1.25.0/install/zowe-install.sh": "#!/bin/sh\n",
+	})
+	installer := New()
+	installer.WorkDir = filepath.Join(home, "work")
+	report, err := installer.Doctor(paxFile)
+	if err != nil {
+		t.Fatalf("Doctor() error = %v", err)
+	}
+	checks := make(map[string]DoctorCheck)
+	for _, check := range report.Checks {
+		checks[check.Name] = check
+	}
+	space := checks["space "+installer.WorkDir]
+	if space.Status != CheckPass || !strings.Contains(space.Message, "needed for the PAX and the expanded PAX") {
+		t.Errorf("space check = %+v", space)
+	}
+	for _, dir := range []string{installer.WorkDir, installer.rootDir, installer.instanceDir} {
+		if check := checks["write "+dir]; check.Status != CheckPass {
+			t.Errorf("write check of %s = %+v", dir, check)
+		}
+	}
+	for _, name := range []string{"user", "group", "java", "node", "tool pax", "tool iconv"} {
+		if _, ok := checks[name]; !ok {
+			t.Errorf("Doctor() didn't check %s", name)
+		}
+	}
+	if _, err := os.Stat(installer.WorkDir); !os.IsNotExist(err) {
+		t.Errorf("Doctor() created %s", installer.WorkDir)
+	}
+}
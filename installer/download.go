@@ -0,0 +1,257 @@
+package installer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	partSuffix = ".part"
+	metaSuffix = ".json"
+)
+
+// partialDownload is stored next to the .part file and holds the validator
+// needed to resume the download with If-Range.
+type partialDownload struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+}
+
+func (p *partialDownload) validator() string {
+	// If-Range requires a strong validator
+	if p.ETag != "" && !strings.HasPrefix(p.ETag, "W/") {
+		return p.ETag
+	}
+	return p.LastModified
+}
+
+func readPartialDownload(metaFile string) (*partialDownload, error) {
+	data, err := ioutil.ReadFile(metaFile)
+	if err != nil {
+		return nil, err
+	}
+	var partial partialDownload
+	if err := json.Unmarshal(data, &partial); err != nil {
+		return nil, err
+	}
+	return &partial, nil
+}
+
+func writePartialDownload(metaFile string, partial *partialDownload) error {
+	data, err := json.Marshal(partial)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(metaFile, data, 0644)
+}
+
+// resumeOffset returns how many bytes of the PAX are already downloaded and the
+// validator to resume from, or 0 if the download has to start from scratch.
+func resumeOffset(partFile string, metaFile string, url string) (int64, string) {
+	fi, err := os.Stat(partFile)
+	if err != nil || fi.Size() == 0 {
+		return 0, ""
+	}
+	partial, err := readPartialDownload(metaFile)
+	if err != nil || partial.URL != url || partial.validator() == "" {
+		return 0, ""
+	}
+	return fi.Size(), partial.validator()
+}
+
+// parseContentRange returns the first byte position of a "bytes first-last/total"
+// Content-Range header.
+func parseContentRange(contentRange string) (int64, error) {
+	value := strings.TrimPrefix(contentRange, "bytes ")
+	if value == contentRange {
+		return 0, errors.Errorf("unsupported Content-Range %q", contentRange)
+	}
+	i := strings.Index(value, "-")
+	if i < 0 {
+		return 0, errors.Errorf("invalid Content-Range %q", contentRange)
+	}
+	return strconv.ParseInt(value[:i], 10, 64)
+}
+
+// requestPax starts downloading the PAX from offset. It returns the response and
+// the offset the response body actually starts at, which is 0 when the server
+// ignores the range.
+func (installer *ZoweInstaller) requestPax(ctx context.Context, url string, offset int64, validator string) (*http.Response, int64, error) {
+	req, err := installer.newRequest(ctx, http.MethodGet, url)
+	if err != nil {
+		return nil, 0, err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		req.Header.Set("If-Range", validator)
+	}
+	client, err := installer.httpClient()
+	if err != nil {
+		return nil, 0, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, errors.Wrapf(err, "failed to download %s", url)
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		if offset > 0 {
+			log.Printf("Server didn't resume the download, starting from the beginning")
+		}
+		return resp, 0, nil
+	case http.StatusPartialContent:
+		start, err := parseContentRange(resp.Header.Get("Content-Range"))
+		if err != nil || start != offset {
+			resp.Body.Close()
+			return nil, 0, errors.Errorf("failed to resume download of %s: unexpected Content-Range %q", url, resp.Header.Get("Content-Range"))
+		}
+		log.Printf("Resuming download at %d bytes", offset)
+		return resp, offset, nil
+	case http.StatusRequestedRangeNotSatisfiable:
+		resp.Body.Close()
+		if offset > 0 {
+			log.Printf("Partial download is no longer valid, starting from the beginning")
+			return installer.requestPax(ctx, url, 0, "")
+		}
+	default:
+		resp.Body.Close()
+	}
+	return nil, 0, &StatusError{URL: url, StatusCode: resp.StatusCode}
+}
+
+// DownloadPax downloads the PAX into a .part file which is renamed once the
+// download is complete and verified. An interrupted download is resumed from the
+// .part file with a Range request, both on retries and on the next run. PAX files
+// from local paths, file:// URLs and stdin are copied. With CacheDir, downloads
+// are taken from and added to the Cache. When a download fails, the Mirrors are
+// tried in turn.
+func (installer *ZoweInstaller) DownloadPax() error {
+	if !isRemote(installer.paxURL) {
+		return installer.copyPax()
+	}
+	if installer.downloadFromCache() {
+		return nil
+	}
+	source, err := installer.fromSources("PAX", func(source string) error {
+		ctx, cancel := installer.withTimeout(context.Background())
+		defer cancel()
+		return installer.retry(ctx, source, func(ctx context.Context) error {
+			return installer.downloadPax(ctx, source)
+		})
+	})
+	if err != nil {
+		return err
+	}
+	log.Printf("Downloaded %s from %s", filepath.Base(installer.paxFileName), source)
+	installer.addToCache()
+	return nil
+}
+
+func (installer *ZoweInstaller) downloadPax(ctx context.Context, url string) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	partFile := installer.paxFileName + partSuffix
+	metaFile := partFile + metaSuffix
+	offset, validator := resumeOffset(partFile, metaFile, url)
+	resp, offset, err := installer.requestPax(ctx, url, offset, validator)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if offset > 0 {
+		flags = os.O_WRONLY | os.O_APPEND
+	} else {
+		partial := partialDownload{
+			URL:          url,
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+		}
+		if err := writePartialDownload(metaFile, &partial); err != nil {
+			return errors.Wrapf(err, "failed to write %s", metaFile)
+		}
+	}
+	out, err := os.OpenFile(partFile, flags, 0644)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create file for pax")
+	}
+	defer out.Close()
+	var writer io.Writer = out
+	h := installer.newHash()
+	if h != nil {
+		writer = io.MultiWriter(out, h)
+		if offset > 0 {
+			if err := hashFile(partFile, h); err != nil {
+				return err
+			}
+		}
+	}
+	total := int64(-1)
+	if resp.ContentLength >= 0 {
+		total = offset + resp.ContentLength
+	}
+	progress := installer.startProgress(StageDownload, offset, total)
+	body := installer.idleTimeoutReader(resp.Body, cancel)
+	_, err = io.Copy(writer, io.TeeReader(body, progress))
+	if err != nil {
+		return progress.finish(errors.Wrapf(err, "failed to read response body"))
+	}
+	if err := out.Close(); err != nil {
+		return progress.finish(errors.Wrapf(err, "failed to write %s", partFile))
+	}
+	return progress.finish(installer.completePax(partFile, h))
+}
+
+func (installer *ZoweInstaller) newHash() hash.Hash {
+	if installer.checksum == nil {
+		return nil
+	}
+	return installer.checksum.newHash()
+}
+
+// completePax verifies the checksum of a complete .part file and renames it to
+// the PAX file.
+func (installer *ZoweInstaller) completePax(partFile string, h hash.Hash) error {
+	metaFile := partFile + metaSuffix
+	if installer.checksum != nil {
+		if err := installer.checksum.verify(installer.paxFileName, h); err != nil {
+			os.Remove(partFile)
+			os.Remove(metaFile)
+			return err
+		}
+		log.Printf("Checksum %s verified", installer.checksum.Algorithm)
+		installer.verified = true
+	}
+	if err := os.Rename(partFile, installer.paxFileName); err != nil {
+		return errors.Wrapf(err, "failed to rename %s", partFile)
+	}
+	// the validators tell later installs from the cache whether the PAX changed
+	installer.downloaded, _ = readPartialDownload(metaFile)
+	os.Remove(metaFile)
+	return nil
+}
+
+func hashFile(file string, h io.Writer) error {
+	in, err := os.Open(file)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open %s", file)
+	}
+	defer in.Close()
+	if _, err := io.Copy(h, in); err != nil {
+		return errors.Wrapf(err, "failed to read %s", file)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package installer
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestZoweInstaller_DownloadPax(t *testing.T) {
+	content := strings.Repeat("zowe pax content ", 1000)
+	tests := []struct {
+		name      string
+		ranges    bool
+		partial   int
+		wantRange string
+	}{
+		{"fresh download", true, 0, ""},
+		{"resume", true, 5000, "bytes=5000-"},
+		{"server without ranges", false, 5000, "bytes=5000-"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotRange string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotRange = r.Header.Get("Range")
+				w.Header().Set("ETag", `"v1"`)
+				if tt.ranges {
+					http.ServeContent(w, r, "zowe.pax", time.Time{}, strings.NewReader(content))
+				} else {
+					w.Write([]byte(content))
+				}
+			}))
+			defer server.Close()
+			dir, err := ioutil.TempDir("", "download")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			installer := New()
+			installer.paxURL = server.URL + "/zowe.pax"
+			installer.paxFileName = filepath.Join(dir, "zowe.pax")
+			partFile := installer.paxFileName + partSuffix
+			if tt.partial > 0 {
+				if err := ioutil.WriteFile(partFile, []byte(content[:tt.partial]), 0644); err != nil {
+					t.Fatal(err)
+				}
+				partial := partialDownload{URL: installer.paxURL, ETag: `"v1"`}
+				if err := writePartialDownload(partFile+metaSuffix, &partial); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := installer.DownloadPax(); err != nil {
+				t.Fatalf("DownloadPax() error = %v", err)
+			}
+			if gotRange != tt.wantRange {
+				t.Errorf("Range = %q, want %q", gotRange, tt.wantRange)
+			}
+			data, err := ioutil.ReadFile(installer.paxFileName)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(data, []byte(content)) {
+				t.Errorf("downloaded %d bytes, want %d", len(data), len(content))
+			}
+			if _, err := os.Stat(partFile); !os.IsNotExist(err) {
+				t.Errorf("%s is left after the download", partFile)
+			}
+		})
+	}
+}
+
+func TestZoweInstaller_DownloadPaxRetry(t *testing.T) {
+	content := strings.Repeat("zowe pax content ", 1000)
+	tests := []struct {
+		name     string
+		failures int
+		status   int
+		stall    bool
+		retries  int
+		wantErr  bool
+	}{
+		{"server error", 2, http.StatusServiceUnavailable, false, 3, false},
+		{"stalled body", 1, http.StatusOK, true, 3, false},
+		{"retries used up", 3, http.StatusBadGateway, false, 2, true},
+		{"not found", 1, http.StatusNotFound, false, 3, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				w.Header().Set("ETag", `"v1"`)
+				if requests <= tt.failures {
+					if tt.stall {
+						w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+						w.Write([]byte(content[:100]))
+						w.(http.Flusher).Flush()
+						<-r.Context().Done()
+						return
+					}
+					w.WriteHeader(tt.status)
+					return
+				}
+				http.ServeContent(w, r, "zowe.pax", time.Time{}, strings.NewReader(content))
+			}))
+			defer server.Close()
+			dir, err := ioutil.TempDir("", "download")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			installer := New()
+			installer.Retries = tt.retries
+			installer.RetryDelay = time.Millisecond
+			installer.ReadTimeout = 100 * time.Millisecond
+			installer.paxURL = server.URL + "/zowe.pax"
+			installer.paxFileName = filepath.Join(dir, "zowe.pax")
+			err = installer.DownloadPax()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DownloadPax() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			data, err := ioutil.ReadFile(installer.paxFileName)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(data, []byte(content)) {
+				t.Errorf("downloaded %d bytes, want %d", len(data), len(content))
+			}
+		})
+	}
+}
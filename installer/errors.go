@@ -0,0 +1,109 @@
+package installer
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+const outputTailSize = 4096
+
+// StageError records which stage of the installation failed. For the Zowe
+// scripts it also holds the command, its exit status and the tail of its output.
+type StageError struct {
+	Stage      Stage
+	Command    []string
+	ExitStatus int
+	Output     string
+	Err        error
+}
+
+func (e *StageError) Error() string {
+	if len(e.Command) > 0 {
+		return fmt.Sprintf("%s failed: %s exited with status %d: %v", e.Stage, strings.Join(e.Command, " "), e.ExitStatus, e.Err)
+	}
+	return fmt.Sprintf("%s failed: %v", e.Stage, e.Err)
+}
+
+func (e *StageError) Unwrap() error {
+	return e.Err
+}
+
+func (e *StageError) Cause() error {
+	return e.Err
+}
+
+// stageError wraps err into a *StageError unless it already is one.
+func stageError(stage Stage, err error) error {
+	if err == nil {
+		return nil
+	}
+	var stageErr *StageError
+	if errors.As(err, &stageErr) {
+		return err
+	}
+	return &StageError{Stage: stage, ExitStatus: -1, Err: err}
+}
+
+// tailBuffer keeps the last bytes written to it.
+type tailBuffer struct {
+	data  []byte
+	mutex sync.Mutex
+}
+
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.data = append(t.data, p...)
+	if len(t.data) > outputTailSize {
+		t.data = t.data[len(t.data)-outputTailSize:]
+	}
+	return len(p), nil
+}
+
+func (t *tailBuffer) String() string {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return string(t.data)
+}
+
+// output returns Stdout or, when it isn't set, a writer discarding the output.
+func (installer *ZoweInstaller) output() io.Writer {
+	if installer.Stdout == nil {
+		return ioutil.Discard
+	}
+	return installer.Stdout
+}
+
+// runScript runs a Zowe script, passing its output through and recording its
+// tail for the *StageError returned when the script fails.
+func (installer *ZoweInstaller) runScript(stage Stage, cmd *exec.Cmd) error {
+	var tail tailBuffer
+	var out io.Writer = &tail
+	if installer.Stdout != nil {
+		out = io.MultiWriter(installer.Stdout, &tail)
+	}
+	cmd.Stdout = out
+	cmd.Stderr = out
+	err := cmd.Run()
+	if err == nil {
+		return nil
+	}
+	exitStatus := -1
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		exitStatus = exitErr.ExitCode()
+	}
+	return &StageError{
+		Stage:      stage,
+		Command:    cmd.Args,
+		ExitStatus: exitStatus,
+		Output:     tail.String(),
+		Err:        err,
+	}
+}
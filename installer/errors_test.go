@@ -0,0 +1,50 @@
+package installer
+
+import (
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestZoweInstaller_runScript(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a POSIX shell")
+	}
+	tests := []struct {
+		name       string
+		script     string
+		wantErr    bool
+		wantStatus int
+		wantOutput string
+	}{
+		{"success", "echo installed", false, 0, ""},
+		{"failure", "echo first line; echo error from script >&2; exit 3", true, 3, "error from script"},
+		{"long output", "i=0; while [ $i -lt 2000 ]; do echo line $i; i=$((i+1)); done; exit 1", true, 1, "line 1999"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			installer := New()
+			installer.Stdout = nil
+			err := installer.runScript(StageInstall, exec.Command("/bin/sh", "-c", tt.script))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("runScript() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil {
+				return
+			}
+			var stageErr *StageError
+			if !errors.As(err, &stageErr) {
+				t.Fatalf("runScript() error = %v, want *StageError", err)
+			}
+			if stageErr.Stage != StageInstall || stageErr.ExitStatus != tt.wantStatus {
+				t.Errorf("StageError = %s %d, want %s %d", stageErr.Stage, stageErr.ExitStatus, StageInstall, tt.wantStatus)
+			}
+			if !strings.Contains(stageErr.Output, tt.wantOutput) || len(stageErr.Output) > outputTailSize {
+				t.Errorf("StageError.Output = %q, want tail containing %q", stageErr.Output, tt.wantOutput)
+			}
+		})
+	}
+}
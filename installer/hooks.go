@@ -0,0 +1,81 @@
+package installer
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// hooksFile lists hook scripts, for example:
+//
+//	{"steps": [{"name": "smoke-test", "after": "configure", "run": ["./smoke-test.sh"]}]}
+//
+// Relative script paths are relative to the file. The scripts run in the work
+// directory, checks run before it is created in the directory of the file.
+type hooksFile struct {
+	Steps []hookScripts `json:"steps"`
+}
+
+type hookScripts struct {
+	Name     Stage    `json:"name"`
+	Before   Stage    `json:"before,omitempty"`
+	After    Stage    `json:"after,omitempty"`
+	Check    []string `json:"check,omitempty"`
+	Run      []string `json:"run"`
+	Rollback []string `json:"rollback,omitempty"`
+}
+
+func (installer *ZoweInstaller) loadHooks(file string) ([]Hook, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read hooks file")
+	}
+	var config hooksFile
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse hooks file %s", file)
+	}
+	dir := filepath.Dir(file)
+	var hooks []Hook
+	for _, scripts := range config.Steps {
+		hooks = append(hooks, Hook{
+			Step: Step{
+				Name:     scripts.Name,
+				Check:    installer.scriptFunc(scripts.Name, dir, scripts.Check, false),
+				Run:      installer.scriptFunc(scripts.Name, dir, scripts.Run, true),
+				Rollback: installer.scriptFunc(scripts.Name, dir, scripts.Rollback, false),
+			},
+			Before: scripts.Before,
+			After:  scripts.After,
+		})
+	}
+	return hooks, nil
+}
+
+// scriptFunc runs the command args with the StepEnv in its environment. The
+// progress of the run scripts is reported under the name of their step.
+func (installer *ZoweInstaller) scriptFunc(stage Stage, dir string, args []string, report bool) StepFunc {
+	if len(args) == 0 {
+		return nil
+	}
+	command := args[0]
+	if !filepath.IsAbs(command) && filepath.Base(command) != command {
+		command = filepath.Join(dir, command)
+	}
+	return func(env *StepEnv) error {
+		cmd := exec.Command(command, args[1:]...)
+		cmd.Dir = env.WorkDir
+		if _, err := os.Stat(env.WorkDir); err != nil {
+			cmd.Dir = dir
+		}
+		cmd.Env = append(os.Environ(), env.Environ()...)
+		if !report {
+			return installer.runScript(stage, cmd)
+		}
+		progress := installer.startProgress(stage, 0, -1)
+		return progress.finish(installer.runScript(stage, cmd))
+	}
+}
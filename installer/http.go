@@ -0,0 +1,116 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// StatusError is returned when a server responds with an unexpected status code.
+type StatusError struct {
+	URL        string
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("failed to download %s: bad status code - %d", e.URL, e.StatusCode)
+}
+
+// httpClient returns the client configured by Options.HTTP with the connect and
+// read timeouts.
+func (installer *ZoweInstaller) httpClient() (*http.Client, error) {
+	if installer.client == nil {
+		transport, err := installer.HTTP.Transport()
+		if err != nil {
+			return nil, err
+		}
+		dialer := &net.Dialer{
+			Timeout:   installer.ConnectTimeout,
+			KeepAlive: 30 * time.Second,
+		}
+		transport.DialContext = dialer.DialContext
+		transport.TLSHandshakeTimeout = installer.ConnectTimeout
+		transport.ResponseHeaderTimeout = installer.ReadTimeout
+		installer.client = &http.Client{Transport: transport}
+	}
+	return installer.client, nil
+}
+
+// withTimeout limits ctx by the overall download timeout, if there is one.
+func (installer *ZoweInstaller) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if installer.Timeout > 0 {
+		return context.WithTimeout(ctx, installer.Timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// get requests url and fails unless the server responds with 200 OK. The caller
+// must close the response body.
+func (installer *ZoweInstaller) get(ctx context.Context, url string) (*http.Response, error) {
+	req, err := installer.newRequest(ctx, http.MethodGet, url)
+	if err != nil {
+		return nil, err
+	}
+	client, err := installer.httpClient()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to download %s", url)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, &StatusError{URL: url, StatusCode: resp.StatusCode}
+	}
+	return resp, nil
+}
+
+// idleReader cancels the request when no data arrives for the read timeout.
+type idleReader struct {
+	r       io.Reader
+	timer   *time.Timer
+	timeout time.Duration
+	fired   int32
+}
+
+// idleTimeoutReader wraps a response body so that cancel is called once the body
+// stalls for longer than the read timeout.
+func (installer *ZoweInstaller) idleTimeoutReader(r io.Reader, cancel context.CancelFunc) io.Reader {
+	if installer.ReadTimeout <= 0 {
+		return r
+	}
+	reader := &idleReader{r: r, timeout: installer.ReadTimeout}
+	reader.timer = time.AfterFunc(installer.ReadTimeout, func() {
+		atomic.StoreInt32(&reader.fired, 1)
+		cancel()
+	})
+	return reader
+}
+
+func (r *idleReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if err != nil {
+		r.timer.Stop()
+		if atomic.LoadInt32(&r.fired) == 1 {
+			err = &idleTimeoutError{r.timeout}
+		}
+		return n, err
+	}
+	r.timer.Reset(r.timeout)
+	return n, nil
+}
+
+type idleTimeoutError struct {
+	timeout time.Duration
+}
+
+func (e *idleTimeoutError) Error() string {
+	return fmt.Sprintf("no data received for %s", e.timeout)
+}
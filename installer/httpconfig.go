@@ -0,0 +1,139 @@
+package installer
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// HTTPConfig configures the HTTP client used for all downloads.
+type HTTPConfig struct {
+	// Proxy is the URL of the proxy for HTTP and HTTPS requests. When empty, the
+	// HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables are used.
+	Proxy string
+	// NoProxy is a comma separated list of hosts, domains, IP addresses and CIDR
+	// ranges reached directly, * for all. It applies to the environment proxy too.
+	NoProxy string
+	// CABundle is a PEM file with CA certificates trusted in addition to the
+	// system ones, for example the CA of a TLS intercepting proxy.
+	CABundle string
+	// ClientCert and ClientKey are PEM files with a client certificate and its
+	// key. ClientKey can be left out when ClientCert holds both.
+	ClientCert string
+	ClientKey  string
+	// Insecure disables the verification of server certificates.
+	Insecure bool
+}
+
+// Transport returns an HTTP transport configured by config.
+func (config *HTTPConfig) Transport() (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	proxy, err := config.proxyFunc()
+	if err != nil {
+		return nil, err
+	}
+	transport.Proxy = proxy
+	tlsConfig, err := config.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
+}
+
+func (config *HTTPConfig) proxyFunc() (func(*http.Request) (*url.URL, error), error) {
+	proxy := http.ProxyFromEnvironment
+	if config.Proxy != "" {
+		proxyURL, err := url.Parse(config.Proxy)
+		if err != nil || proxyURL.Host == "" {
+			return nil, errors.Errorf("invalid proxy URL %s", RedactURL(config.Proxy))
+		}
+		proxy = http.ProxyURL(proxyURL)
+	}
+	if config.NoProxy == "" {
+		return proxy, nil
+	}
+	return func(req *http.Request) (*url.URL, error) {
+		if bypassProxy(req.URL, config.NoProxy) {
+			return nil, nil
+		}
+		return proxy(req)
+	}, nil
+}
+
+// bypassProxy reports whether u matches an entry of the noProxy list.
+func bypassProxy(u *url.URL, noProxy string) bool {
+	host := u.Hostname()
+	port := u.Port()
+	ip := net.ParseIP(host)
+	for _, entry := range strings.Split(noProxy, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if entry == "*" {
+			return true
+		}
+		if _, cidr, err := net.ParseCIDR(entry); err == nil {
+			if ip != nil && cidr.Contains(ip) {
+				return true
+			}
+			continue
+		}
+		if h, p, err := net.SplitHostPort(entry); err == nil {
+			if p != port {
+				continue
+			}
+			entry = h
+		}
+		entry = strings.TrimPrefix(strings.TrimPrefix(entry, "*"), ".")
+		host := strings.ToLower(host)
+		if host == entry || strings.HasSuffix(host, "."+entry) {
+			return true
+		}
+	}
+	return false
+}
+
+func (config *HTTPConfig) tlsConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{}
+	if config.CABundle != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		data, err := ioutil.ReadFile(config.CABundle)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read CA bundle")
+		}
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, errors.Errorf("no certificates found in CA bundle %s", config.CABundle)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if config.ClientCert != "" {
+		keyFile := config.ClientKey
+		if keyFile == "" {
+			keyFile = config.ClientCert
+		}
+		cert, err := tls.LoadX509KeyPair(config.ClientCert, keyFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to load client certificate %s", config.ClientCert)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	} else if config.ClientKey != "" {
+		return nil, errors.New("client key given without a client certificate")
+	}
+	if config.Insecure {
+		log.Printf("WARNING: server certificates are not verified")
+		tlsConfig.InsecureSkipVerify = true
+	}
+	return tlsConfig, nil
+}
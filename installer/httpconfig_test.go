@@ -0,0 +1,104 @@
+package installer
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_bypassProxy(t *testing.T) {
+	tests := []struct {
+		url     string
+		noProxy string
+		want    bool
+	}{
+		{"https://zowe.org/zowe.pax", "", false},
+		{"https://zowe.org/zowe.pax", "*", true},
+		{"https://zowe.org/zowe.pax", "zowe.org", true},
+		{"https://downloads.zowe.org/zowe.pax", "zowe.org", true},
+		{"https://downloads.zowe.org/zowe.pax", ".zowe.org", true},
+		{"https://notzowe.org/zowe.pax", "zowe.org", false},
+		{"https://mirror.corp:8443/zowe.pax", "example.com, mirror.corp:8443", true},
+		{"https://mirror.corp/zowe.pax", "mirror.corp:8443", false},
+		{"http://10.1.2.3/zowe.pax", "10.0.0.0/8", true},
+		{"http://192.168.1.1/zowe.pax", "10.0.0.0/8", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url+" "+tt.noProxy, func(t *testing.T) {
+			u, _ := url.Parse(tt.url)
+			if got := bypassProxy(u, tt.noProxy); got != tt.want {
+				t.Errorf("bypassProxy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTTPConfig_Transport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "httpconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) == 0 {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write([]byte("pax"))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequestClientCert}
+	server.StartTLS()
+	defer server.Close()
+	cert := server.TLS.Certificates[0]
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	key, _ := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+	ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]}), 0644)
+	ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: key}), 0600)
+	tests := []struct {
+		name       string
+		config     HTTPConfig
+		wantStatus int
+		wantErr    bool
+	}{
+		{"untrusted", HTTPConfig{}, 0, true},
+		{"CA bundle", HTTPConfig{CABundle: certFile}, http.StatusForbidden, false},
+		{"insecure", HTTPConfig{Insecure: true}, http.StatusForbidden, false},
+		{"client certificate", HTTPConfig{CABundle: certFile, ClientCert: certFile, ClientKey: keyFile}, http.StatusOK, false},
+		{"proxy bypassed", HTTPConfig{CABundle: certFile, Proxy: "http://127.0.0.1:1", NoProxy: "127.0.0.1"}, http.StatusForbidden, false},
+		{"unreachable proxy", HTTPConfig{CABundle: certFile, Proxy: "http://127.0.0.1:1"}, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport, err := tt.config.Transport()
+			if err != nil {
+				t.Fatalf("Transport() error = %v", err)
+			}
+			resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("Get() status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+	if _, err := (&HTTPConfig{CABundle: keyFile}).Transport(); err == nil {
+		t.Errorf("Transport() accepted a CA bundle without certificates")
+	}
+	if _, err := (&HTTPConfig{Proxy: "http://proxy:s3cret@"}).Transport(); err == nil || strings.Contains(err.Error(), "s3cret") {
+		t.Errorf("Transport() error = %v, want an error without the password", err)
+	}
+}
@@ -5,172 +5,571 @@ import (
 	"io"
 	"log"
 	"net/http"
-	"net/url"
 	"os"
 	"os/exec"
 	"os/user"
-	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
-	humanize "github.com/dustin/go-humanize"
+	paxarchive "github.com/lchudinov/zowe_installer/pax"
 	"github.com/pkg/errors"
 )
 
-type ByteCounter struct {
-	Total uint64
-}
-
-func (bc *ByteCounter) Write(p []byte) (n int, err error) {
-	len := len(p)
-	bc.Total += uint64(len)
-	bc.PrintProgress()
-	return n, nil
-}
-
-func (bc *ByteCounter) PrintProgress() {
-	fmt.Printf("\r%s", strings.Repeat(" ", 35))
-	fmt.Printf("\rDownloading... %s complete", humanize.Bytes(bc.Total))
+// Options control how ZoweInstaller downloads and verifies the PAX.
+type Options struct {
+	// Checksum is the expected digest of the PAX, see ParseChecksum.
+	Checksum string
+	// VerifySidecar fetches the expected digest from the .sha512 file published
+	// next to the PAX when Checksum is empty.
+	VerifySidecar bool
+	// Keyring is an armored OpenPGP public keyring. When set, the detached
+	// signature of the PAX is checked before extraction.
+	Keyring string
+	// SignatureURL is the location of the detached signature, by default the PAX
+	// URL with the .asc suffix.
+	SignatureURL string
+	// ConnectTimeout limits establishing a connection and the TLS handshake.
+	ConnectTimeout time.Duration
+	// ReadTimeout limits waiting for the response headers and for each chunk of
+	// the response body.
+	ReadTimeout time.Duration
+	// Timeout limits a whole download including retries, 0 means no limit. With
+	// mirrors, it applies to each of them.
+	Timeout time.Duration
+	// HTTP configures the proxy and TLS settings of downloads.
+	HTTP HTTPConfig
+	// Credentials authenticate all downloads unless their Host is set, see
+	// CredentialsFromEnv. User info in a URL takes precedence for its host.
+	Credentials Credentials
+	// CredentialsFile is a JSON file with credentials per host, used when
+	// Credentials don't apply.
+	CredentialsFile string
+	// NetrcFile is a .netrc file used for hosts without other credentials.
+	NetrcFile string
+	// Retries is how many times a download is retried after a transient error.
+	Retries int
+	// RetryDelay is the delay before the first retry, it doubles with every retry.
+	RetryDelay time.Duration
+	// Mirrors are tried in turn when downloading from the PAX URL fails. A
+	// mirror ending with / is a directory holding the PAX, otherwise it is the
+	// URL of the PAX itself. Each mirror gets the same checksum verification.
+	Mirrors []string
+	// MirrorsFile lists more mirrors, one per line.
+	MirrorsFile string
+	// CacheDir is the Cache of downloaded PAX files, empty for none. Cached
+	// files are found by their digest when the checksum is known and by their
+	// URL otherwise.
+	CacheDir string
+	// PaxName is the file name of the PAX, by default taken from its location.
+	// It is required when the PAX is read from stdin.
+	PaxName string
+	// WorkDir is where the PAX is downloaded and extracted, by default
+	// $HOME/<PAX name>.
+	WorkDir string
+	// RootDir is the ROOT_DIR Zowe is installed into, by default <WorkDir>/root.
+	RootDir string
+	// VersionsDir holds side-by-side versioned installs. When set, the ROOT_DIR
+	// is <VersionsDir>/zowe-<version> and the CurrentLink in it is pointed at
+	// the new version. It can't be combined with RootDir.
+	VersionsDir string
+	// InstanceDir is the INSTANCE_DIR, by default <WorkDir>/instance or
+	// <VersionsDir>/instance for versioned installs. The instance of versioned
+	// installs is configured by the first version and kept by later ones.
+	InstanceDir string
+	// LogDir is where zowe-install.sh writes its log, by default the script's own
+	// default location.
+	LogDir string
+	// User is the user, by name or id, passed to zowe-install.sh, by default
+	// the current user.
+	User string
+	// Group is the group, by name or id, passed to zowe-configure-instance.sh,
+	// by default the primary group of User.
+	Group string
+	// Extensions are component archives or directories installed with
+	// zowe-install-component.sh once the instance is configured.
+	Extensions []string
+	// InstanceEnv overrides variables of the instance.env of the new instance.
+	InstanceEnv map[string]string
+	// Force allows deleting the contents of existing work, root and instance
+	// directories. A partial download of the same PAX is kept to be resumed.
+	// The previous root and instance directories are only deleted once the new
+	// ones are in place.
+	Force bool
+	// Backup moves existing directories to timestamped backups instead.
+	Backup bool
+	// Resume skips the stages completed by an earlier run of the same
+	// installation after checking that their outputs are still in place.
+	Resume bool
+	// Rollback undoes the steps run so far when a step fails, for example
+	// removes the extracted PAX. The previous ROOT_DIR and INSTANCE_DIR are
+	// restored on failure regardless.
+	Rollback bool
+	// Hooks are site-specific steps added to the install pipeline.
+	Hooks []Hook
+	// HooksFile is a JSON file listing hook scripts to add to the pipeline.
+	HooksFile string
+	// Progress receives the progress events of all stages.
+	Progress ProgressReporter
+	// Stdout receives the output of the Zowe scripts and the messages of the
+	// installer that aren't progress events.
+	Stdout io.Writer
 }
 
 type ZoweInstaller struct {
+	Options
 	paxURL      string
 	paxFileName string
 	dir         string
 	rootDir     string
 	instanceDir string
+	logDir      string
+	checksum    *Checksum
+	verified    bool
+	signed      bool
+	client      *http.Client
+	journal     *journal
+	extracted   string
+	manifest    *Manifest
+	// credentials are loaded on first use, urlCredentials come from the user
+	// info of URLs by host
+	credentials    []Credentials
+	urlCredentials map[string]*Credentials
+	// downloaded holds the validators of the completed download
+	downloaded *partialDownload
 }
 
 func New() *ZoweInstaller {
-	installer := ZoweInstaller{}
+	installer := ZoweInstaller{
+		Options: Options{
+			ConnectTimeout: 30 * time.Second,
+			ReadTimeout:    time.Minute,
+			Retries:        5,
+			RetryDelay:     time.Second,
+			Progress:       NewTerminalReporter(os.Stdout),
+			Stdout:         os.Stdout,
+		},
+	}
 	return &installer
 }
 
+// Install runs the install pipeline for the PAX at paxURL, see Step and Hook.
+// Failures are returned as *StageError. With Resume, steps completed by an
+// earlier run are skipped as long as their outputs are still in place.
 func (installer *ZoweInstaller) Install(paxURL string) error {
+	steps, err := installer.pipeline()
+	if err != nil {
+		return stageError(StagePrepare, err)
+	}
+	if err := installer.resolve(paxURL); err != nil {
+		return stageError(StagePrepare, err)
+	}
+	if err := installer.checkSteps(steps); err != nil {
+		return stageError(StagePrepare, err)
+	}
 	if err := installer.PrepareInstallation(paxURL); err != nil {
+		return stageError(StagePrepare, err)
+	}
+	return installer.runSteps(steps)
+}
+
+// PrepareInstallation creates the installation directory for the PAX at paxURL,
+// which can be an HTTP(S) URL, a file:// URL, a local path or Stdin. Trees an
+// interrupted run moved aside are restored first.
+func (installer *ZoweInstaller) PrepareInstallation(paxURL string) error {
+	if err := installer.resolve(paxURL); err != nil {
 		return err
 	}
-	if err := installer.DownloadPax(); err != nil {
+	if err := installer.restoreInterrupted(); err != nil {
 		return err
 	}
-	if err := installer.ExtractPax(); err != nil {
-		return nil
+	installer.journal = nil
+	if installer.Resume {
+		installer.journal = installer.loadJournal()
 	}
-	if err := installer.InstallPax(); err != nil {
+	if installer.journal == nil {
+		if err := installer.prepareTargets(); err != nil {
+			return err
+		}
+		installer.journal = installer.newJournal()
+	}
+	if err := installer.createWorkDir(); err != nil {
+		return errors.Wrapf(err, "failed to create directory for installation")
+	}
+	if err := installer.journal.save(); err != nil {
+		return err
+	}
+	installer.verified = false
+	installer.signed = false
+	return installer.resolveChecksum()
+}
+
+// resolve works out the PAX file name and the directories without touching the
+// file system.
+func (installer *ZoweInstaller) resolve(paxURL string) error {
+	paxFile := installer.PaxName
+	if paxFile == "" {
+		name, err := sourceName(paxURL)
+		if err != nil {
+			return err
+		}
+		paxFile = name
+	}
+	if err := installer.resolveDirs(paxFile); err != nil {
 		return err
 	}
-	if err := installer.InitInstance(); err != nil {
+	installer.paxFileName = filepath.Join(installer.dir, paxFile)
+	installer.paxURL = installer.withoutCredentials(paxURL)
+	return nil
+}
+
+// resolveDirs works out the absolute work, root, instance and log directories.
+// They have to be absolute because the Zowe scripts run in their own directories.
+func (installer *ZoweInstaller) resolveDirs(paxFile string) error {
+	dir := installer.WorkDir
+	if dir == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return errors.Wrapf(err, "failed to get user home dir")
+		}
+		dir = filepath.Join(homeDir, strings.TrimSuffix(paxFile, filepath.Ext(paxFile)))
+	}
+	rootDir := installer.RootDir
+	if installer.VersionsDir != "" {
+		if rootDir != "" {
+			return errors.New("the root dir can't be set for versioned installs")
+		}
+		rootDir = versionDir(installer.VersionsDir, paxVersion(paxFile))
+	} else if rootDir == "" {
+		rootDir = filepath.Join(dir, "root")
+	}
+	instanceDir := installer.InstanceDir
+	if instanceDir == "" && installer.VersionsDir != "" {
+		instanceDir = filepath.Join(installer.VersionsDir, "instance")
+	} else if instanceDir == "" {
+		instanceDir = filepath.Join(dir, "instance")
+	}
+	dirs := []*string{&dir, &rootDir, &instanceDir}
+	logDir := installer.LogDir
+	if logDir != "" {
+		dirs = append(dirs, &logDir)
+	}
+	for _, d := range dirs {
+		abs, err := filepath.Abs(*d)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get absolute path of %s", *d)
+		}
+		*d = abs
+	}
+	installer.dir = dir
+	installer.rootDir = rootDir
+	installer.instanceDir = instanceDir
+	installer.logDir = logDir
+	return nil
+}
+
+var errStdinSidecar = errors.New("can't locate the checksum file of a PAX read from stdin")
+
+func (installer *ZoweInstaller) resolveChecksum() error {
+	installer.checksum = nil
+	if installer.Checksum != "" {
+		checksum, err := ParseChecksum(installer.Checksum)
+		if err != nil {
+			return err
+		}
+		installer.checksum = checksum
+	} else if installer.VerifySidecar {
+		if installer.paxURL == Stdin {
+			return errStdinSidecar
+		}
+		var checksum *Checksum
+		source, err := installer.fromSources("PAX checksum", func(source string) error {
+			var err error
+			checksum, err = installer.fetchChecksum(source+"."+SHA512, SHA512)
+			return err
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to get PAX checksum")
+		}
+		log.Printf("Expected checksum %s from %s.%s", checksum, source, SHA512)
+		installer.checksum = checksum
+	}
+	return nil
+}
+
+func (installer *ZoweInstaller) VerifyPaxSignature() error {
+	if installer.Keyring == "" {
 		return nil
 	}
+	signatureFile := installer.paxFileName + ".asc"
+	var err error
+	if installer.SignatureURL != "" {
+		err = installer.fetchFile(installer.withoutCredentials(installer.SignatureURL), signatureFile)
+	} else if installer.paxURL == Stdin {
+		return errors.New("the signature location is required for a PAX read from stdin")
+	} else {
+		_, err = installer.fromSources("PAX signature", func(source string) error {
+			return installer.fetchFile(source+".asc", signatureFile)
+		})
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to get PAX signature")
+	}
+	report := VerifySignature(installer.paxFileName, signatureFile, installer.Keyring)
+	fmt.Fprint(installer.output(), report)
+	if !report.Valid() {
+		return errors.Wrapf(report.Err, "failed to verify signature of %s", installer.paxFileName)
+	}
+	installer.signed = true
 	return nil
 }
 
-func (installer *ZoweInstaller) PrepareInstallation(paxURL string) error {
-	url, err := url.Parse(paxURL)
+func (installer *ZoweInstaller) ExtractPax() error {
+	pax := installer.paxFileName
+	if installer.checksum != nil && !installer.verified {
+		return errors.Errorf("refusing to extract %s: checksum not verified", pax)
+	}
+	if installer.Keyring != "" && !installer.signed {
+		return errors.Errorf("refusing to extract %s: signature not verified", pax)
+	}
+	workDir := filepath.Dir(pax)
+	file, err := os.Open(pax)
 	if err != nil {
-		return errors.Wrapf(err, "failed to parse PAX URL %s\n", paxURL)
+		return errors.Wrapf(err, "failed to open %s", pax)
 	}
-	paxFile := path.Base(url.Path)
-	ext := path.Ext(paxFile)
-	dir := strings.TrimSuffix(paxFile, ext)
-	homeDir, err := os.UserHomeDir()
+	defer file.Close()
+	total := int64(-1)
+	if fi, err := file.Stat(); err == nil {
+		total = fi.Size()
+	}
+	progress := installer.startProgress(StageExtract, 0, total)
+	topLevel := make(map[string]bool)
+	err = paxarchive.Extract(io.TeeReader(file, progress), workDir, func(entry *paxarchive.Entry) (bool, error) {
+		switch entry.Type {
+		case paxarchive.TypeFile, paxarchive.TypeDir, paxarchive.TypeSymlink, paxarchive.TypeLink:
+			if name := topLevelName(entry.Name); name != "." {
+				topLevel[name] = true
+			}
+		}
+		return true, nil
+	})
 	if err != nil {
-		return errors.Wrapf(err, "failed to get user home dir")
+		return progress.finish(errors.Wrapf(err, "error unpacking %s", pax))
 	}
-	installer.dir = filepath.Join(homeDir, dir)
-	if err := os.RemoveAll(installer.dir); err != nil {
-		return errors.Wrapf(err, "failed to cleanup installation dir")
+	dir, manifest, err := extractedRoot(workDir, topLevel)
+	if err != nil {
+		return progress.finish(err)
 	}
-	if err := os.MkdirAll(installer.dir, 0755); err != nil {
-		return errors.Wrapf(err, "failed to create directory for installation")
+	installer.extracted = dir
+	installer.manifest = manifest
+	if installer.journal != nil {
+		installer.journal.Extracted = dir
 	}
-	installer.paxFileName = filepath.Join(installer.dir, paxFile)
-	installer.paxURL = paxURL
-	return nil
+	log.Printf("Extracted Zowe %s build %s with %d components", manifest.Version, manifest.Build.Number, len(manifest.Components))
+	if installer.VersionsDir != "" && manifest.Version != paxVersion(filepath.Base(pax)) {
+		log.Printf("WARNING: PAX %s holds Zowe %s, it is installed into %s", filepath.Base(pax), manifest.Version, installer.rootDir)
+	}
+	return progress.finish(nil)
 }
 
-func (installer *ZoweInstaller) DownloadPax() (err error) {
-	out, err := os.Create(installer.paxFileName)
+// installUser is the user name passed to zowe-install.sh, Options.User or the
+// current user.
+func (installer *ZoweInstaller) installUser() (string, error) {
+	if installer.User != "" {
+		userInfo, err := lookupUser(installer.User)
+		if err != nil {
+			return "", err
+		}
+		return userInfo.Username, nil
+	}
+	userInfo, err := user.Current()
 	if err != nil {
-		err = errors.Wrapf(err, "failed to create file for pax")
-		return
+		return "", errors.Wrapf(err, "failed to get current user")
+	}
+	return userInfo.Username, nil
+}
+
+// instanceGroup is the group passed to zowe-configure-instance.sh, Options.Group
+// or the primary group of the install user.
+func (installer *ZoweInstaller) instanceGroup() (string, error) {
+	if installer.Group != "" {
+		groupInfo, err := lookupGroup(installer.Group)
+		if err != nil {
+			return "", err
+		}
+		return groupInfo.Name, nil
+	}
+	userInfo, err := user.Current()
+	if installer.User != "" {
+		userInfo, err = lookupUser(installer.User)
 	}
-	defer out.Close()
-	resp, err := http.Get(installer.paxURL)
 	if err != nil {
-		return
+		return "", errors.Wrapf(err, "failed to get current user")
 	}
-	if resp.StatusCode != http.StatusOK {
-		err = errors.Errorf("bad status code - %d", resp.StatusCode)
-		return
+	groupInfo, err := user.LookupGroupId(userInfo.Gid)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get group name for user %s", userInfo.Username)
 	}
-	defer resp.Body.Close()
-	var counter ByteCounter
-	_, err = io.Copy(out, io.TeeReader(resp.Body, &counter))
+	return groupInfo.Name, nil
+}
+
+// lookupUser finds a user by name or by numeric id.
+func lookupUser(name string) (*user.User, error) {
+	userInfo, err := user.Lookup(name)
 	if err != nil {
-		errors.Wrapf(err, "failed to read response body")
-		return
+		if _, convErr := strconv.Atoi(name); convErr == nil {
+			if userInfo, idErr := user.LookupId(name); idErr == nil {
+				return userInfo, nil
+			}
+		}
+		return nil, errors.Wrapf(err, "failed to find user %s", name)
 	}
-	return
+	return userInfo, nil
 }
 
-func (installer *ZoweInstaller) ExtractPax() error {
-	pax := installer.paxFileName
-	workDir := filepath.Dir(pax)
-	cmd := exec.Command("pax", "-rvf", pax)
-	cmd.Dir = workDir
-	err := cmd.Run()
+// lookupGroup finds a group by name or by numeric id.
+func lookupGroup(name string) (*user.Group, error) {
+	groupInfo, err := user.LookupGroup(name)
 	if err != nil {
-		return errors.Wrapf(err, "error unpacking %s", pax)
+		if _, convErr := strconv.Atoi(name); convErr == nil {
+			if groupInfo, idErr := user.LookupGroupId(name); idErr == nil {
+				return groupInfo, nil
+			}
+		}
+		return nil, errors.Wrapf(err, "failed to find group %s", name)
 	}
-	return nil
+	return groupInfo, nil
+}
+
+func (installer *ZoweInstaller) installCommand(rootDir string) (*exec.Cmd, error) {
+	user, err := installer.installUser()
+	if err != nil {
+		return nil, err
+	}
+	args := []string{"-i", rootDir, "-h", user}
+	if installer.logDir != "" {
+		args = append(args, "-l", installer.logDir)
+	}
+	cmd := exec.Command("./zowe-install.sh", args...)
+	if dir, err := installer.installDir(); err == nil {
+		cmd.Dir = dir
+	}
+	return cmd, nil
 }
 
+func (installer *ZoweInstaller) configureCommand(instanceDir string) (*exec.Cmd, error) {
+	group, err := installer.instanceGroup()
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.Command("./zowe-configure-instance.sh", "-c", instanceDir, "-g", group)
+	cmd.Dir = filepath.Join(installer.activeRootDir(), "bin")
+	return cmd, nil
+}
+
+// InstallPax moves the previous ROOT_DIR aside and runs zowe-install.sh into
+// its place, so that the files it generates refer to the final ROOT_DIR. The
+// previous tree is restored when a step fails, see stage.
 func (installer *ZoweInstaller) InstallPax() error {
-	dir := installer.dir
-	folder := filepath.Base(dir)[0:11]
-	installDir := filepath.Join(dir, folder, "install")
+	installDir, err := installer.installDir()
+	if err != nil {
+		return err
+	}
 	if _, err := os.Stat(installDir); err != nil {
-		return errors.Wrapf(err, "failed to find install dir %s: %v", installDir)
+		return errors.Wrapf(err, "failed to find install dir %s", installDir)
 	}
-	rootDir := filepath.Join(dir, "root")
-	user, err := user.Current()
+	if installer.logDir != "" {
+		if err := os.MkdirAll(installer.logDir, 0755); err != nil {
+			return errors.Wrapf(err, "failed to create log dir %s", installer.logDir)
+		}
+	}
+	if _, err := installer.stage(installer.rootDir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(installer.rootDir, 0755); err != nil {
+		return errors.Wrapf(err, "failed to create %s", installer.rootDir)
+	}
+	cmd, err := installer.installCommand(installer.rootDir)
 	if err != nil {
-		return errors.Wrapf(err, "failed to get current user")
+		return err
+	}
+	progress := installer.startProgress(StageInstall, 0, -1)
+	if err := progress.finish(installer.runScript(StageInstall, cmd)); err != nil {
+		return err
 	}
-	cmd := exec.Command("./zowe-install.sh", "-i", rootDir, "-h", user.Username)
-	cmd.Dir = installDir
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	if err := cmd.Run(); err != nil {
-		return errors.Wrapf(err, "installation failed")
+	if err := installer.writeInstallInfo(installer.rootDir); err != nil {
+		return errors.Wrapf(err, "failed to write install info")
+	}
+	if installer.VersionsDir != "" {
+		return installer.linkCurrent()
 	}
 	return nil
 }
 
+// InitInstance moves the previous INSTANCE_DIR aside, runs
+// zowe-configure-instance.sh into its place, applies the InstanceEnv overrides
+// and installs the Extensions. The previous instance is restored when a step
+// fails, see stage. The configured instance of a versioned install is kept and
+// only gets the overrides and Extensions.
 func (installer *ZoweInstaller) InitInstance() error {
-	dir := installer.dir
-	instanceDir := filepath.Join(dir, "instance")
-	if err := os.Mkdir(instanceDir, 0755); err != nil {
-		return errors.Wrapf(err, "failed to create instance dir %s", instanceDir)
+	if installer.keepsInstance() {
+		log.Printf("Keeping instance %s, it runs the current version", installer.instanceDir)
+	} else if err := installer.configureInstance(); err != nil {
+		return err
 	}
-	log.Printf("Configuring instance..")
-	rootDir := filepath.Join(dir, "root")
-	userInfo, err := user.Current()
+	if err := installer.applyInstanceEnv(installer.instanceDir); err != nil {
+		return err
+	}
+	for _, extension := range installer.Extensions {
+		cmd, err := installer.extensionCommand(extension)
+		if err != nil {
+			return err
+		}
+		log.Printf("Installing extension %s", extension)
+		if err := installer.runScript(StageConfigure, cmd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (installer *ZoweInstaller) configureInstance() error {
+	if _, err := installer.stage(installer.instanceDir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(installer.instanceDir, 0755); err != nil {
+		return errors.Wrapf(err, "failed to create %s", installer.instanceDir)
+	}
+	cmd, err := installer.configureCommand(installer.instanceDir)
 	if err != nil {
-		return errors.Wrapf(err, "failed to get current user")
+		return err
 	}
-	rootBinDir := filepath.Join(rootDir, "bin")
-	groupInfo, err := user.LookupGroupId(userInfo.Gid)
+	progress := installer.startProgress(StageConfigure, 0, -1)
+	return progress.finish(installer.runScript(StageConfigure, cmd))
+}
+
+// extensionCommand installs an extension into the configured instance.
+func (installer *ZoweInstaller) extensionCommand(extension string) (*exec.Cmd, error) {
+	extension, err := filepath.Abs(extension)
 	if err != nil {
-		return errors.Wrapf(err, "failed to get group name for user %s", userInfo.Name)
+		return nil, errors.Wrapf(err, "failed to get absolute path of extension")
+	}
+	cmd := exec.Command("./zowe-install-component.sh", "-o", extension, "-c", installer.instanceDir)
+	cmd.Dir = filepath.Join(installer.activeRootDir(), "bin")
+	return cmd, nil
+}
+
+// checkInstanceConfig checks the extensions and the instance.env overrides.
+func (installer *ZoweInstaller) checkInstanceConfig() error {
+	spec := Spec{Extensions: installer.Extensions, InstanceEnv: installer.InstanceEnv}
+	if err := spec.Validate(); err != nil {
+		return err
 	}
-	cmd := exec.Command("./zowe-configure-instance.sh", "-c", instanceDir, "-g", groupInfo.Name)
-	cmd.Dir = rootBinDir
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	if err := cmd.Run(); err != nil {
-		return errors.Wrapf(err, "failed to configure instance")
+	for _, extension := range installer.Extensions {
+		if _, err := os.Stat(extension); err != nil {
+			return errors.Wrapf(err, "failed to find extension")
+		}
 	}
 	return nil
 }
@@ -0,0 +1,51 @@
+package installer
+
+import (
+	"os/user"
+	"runtime"
+	"testing"
+)
+
+func TestZoweInstaller_installUser(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("user and group databases differ on Windows")
+	}
+	current, err := user.Current()
+	if err != nil {
+		t.Fatal(err)
+	}
+	group, err := user.LookupGroupId(current.Gid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name      string
+		user      string
+		group     string
+		wantUser  string
+		wantGroup string
+		wantErr   bool
+	}{
+		{"defaults", "", "", current.Username, group.Name, false},
+		{"by name", current.Username, group.Name, current.Username, group.Name, false},
+		{"by id", current.Uid, current.Gid, current.Username, group.Name, false},
+		{"group of user", current.Username, "", current.Username, group.Name, false},
+		{"unknown user", "no-such-zowe-user", group.Name, "", group.Name, true},
+		{"unknown group", current.Username, "no-such-zowe-group", current.Username, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			installer := New()
+			installer.User = tt.user
+			installer.Group = tt.group
+			userName, userErr := installer.installUser()
+			groupName, groupErr := installer.instanceGroup()
+			if (userErr != nil || groupErr != nil) != tt.wantErr {
+				t.Fatalf("installUser() error = %v, instanceGroup() error = %v, wantErr %v", userErr, groupErr, tt.wantErr)
+			}
+			if userName != tt.wantUser || groupName != tt.wantGroup {
+				t.Errorf("installUser(), instanceGroup() = %s, %s, want %s, %s", userName, groupName, tt.wantUser, tt.wantGroup)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package installer
+
+import (
+	"crypto/sha512"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const journalFile = ".zowe-install-state.json"
+
+// journal records the completed stages of an installation in the work directory
+// so that a rerun with Resume can skip them.
+type journal struct {
+	file        string
+	Source      string            `json:"source"`
+	PaxFile     string            `json:"paxFile"`
+	RootDir     string            `json:"rootDir"`
+	InstanceDir string            `json:"instanceDir"`
+	Stages      []completedStage  `json:"stages"`
+	Digests     map[string]string `json:"digests,omitempty"`
+	Staged      []*stagedDir      `json:"staged,omitempty"`
+	Extracted   string            `json:"extracted,omitempty"`
+	Started     time.Time         `json:"started"`
+}
+
+type completedStage struct {
+	Stage Stage     `json:"stage"`
+	Time  time.Time `json:"time"`
+}
+
+func (installer *ZoweInstaller) newJournal() *journal {
+	return &journal{
+		file:        filepath.Join(installer.dir, journalFile),
+		Source:      installer.paxURL,
+		PaxFile:     installer.paxFileName,
+		RootDir:     installer.rootDir,
+		InstanceDir: installer.instanceDir,
+		Digests:     make(map[string]string),
+		Started:     time.Now(),
+	}
+}
+
+// loadJournal reads the journal of an earlier run. It returns nil if there is
+// none or if it was written for a different PAX or different directories.
+func (installer *ZoweInstaller) loadJournal() *journal {
+	j := installer.newJournal()
+	data, err := ioutil.ReadFile(j.file)
+	if err != nil {
+		return nil
+	}
+	var saved journal
+	if err := json.Unmarshal(data, &saved); err != nil {
+		log.Printf("Ignoring unreadable install state %s: %v", j.file, err)
+		return nil
+	}
+	if saved.Source != j.Source || saved.PaxFile != j.PaxFile || saved.RootDir != j.RootDir || saved.InstanceDir != j.InstanceDir {
+		log.Printf("Ignoring install state %s of a different installation", j.file)
+		return nil
+	}
+	saved.file = j.file
+	if saved.Digests == nil {
+		saved.Digests = make(map[string]string)
+	}
+	return &saved
+}
+
+func (j *journal) save() error {
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := j.file + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return errors.Wrapf(err, "failed to write install state %s", tmp)
+	}
+	return os.Rename(tmp, j.file)
+}
+
+func (j *journal) completed(stage Stage) bool {
+	for _, s := range j.Stages {
+		if s.Stage == stage {
+			return true
+		}
+	}
+	return false
+}
+
+func (j *journal) complete(stage Stage) error {
+	if !j.completed(stage) {
+		j.Stages = append(j.Stages, completedStage{Stage: stage, Time: time.Now()})
+	}
+	return j.save()
+}
+
+// reset forgets stage and all the stages completed after it.
+func (j *journal) reset(stage Stage) {
+	for i, s := range j.Stages {
+		if s.Stage == stage {
+			j.Stages = j.Stages[:i]
+			return
+		}
+	}
+}
+
+// fileDigest returns the SHA-512 of file as a Checksum string.
+func fileDigest(file string) (string, error) {
+	h := sha512.New()
+	if err := hashFile(file, h); err != nil {
+		return "", err
+	}
+	return (&Checksum{Algorithm: SHA512, Digest: h.Sum(nil)}).String(), nil
+}
+
+func fileExists(file string) error {
+	_, err := os.Stat(file)
+	return err
+}
+
+// verifyStage checks that the outputs of a stage completed in an earlier run
+// are still in place.
+func (installer *ZoweInstaller) verifyStage(stage Stage) error {
+	j := installer.journal
+	switch stage {
+	case StageDownload:
+		digest, err := fileDigest(installer.paxFileName)
+		if err != nil {
+			return err
+		}
+		if digest != j.Digests[installer.paxFileName] {
+			return errors.Errorf("%s changed since it was downloaded", installer.paxFileName)
+		}
+		if installer.checksum != nil {
+			h := installer.checksum.newHash()
+			if err := hashFile(installer.paxFileName, h); err != nil {
+				return err
+			}
+			if err := installer.checksum.verify(installer.paxFileName, h); err != nil {
+				return err
+			}
+			installer.verified = true
+		}
+	case StageVerify:
+		if installer.Keyring == "" {
+			return nil
+		}
+		report := VerifySignature(installer.paxFileName, installer.paxFileName+".asc", installer.Keyring)
+		if !report.Valid() {
+			return report.Err
+		}
+		installer.signed = true
+	case StageExtract:
+		if j.Extracted == "" {
+			return errors.New("the extracted PAX directory is not recorded")
+		}
+		installDir, err := installer.installDir()
+		if err != nil {
+			return err
+		}
+		if err := fileExists(installDir); err != nil {
+			return err
+		}
+		manifest, err := readManifest(j.Extracted)
+		if err != nil {
+			return err
+		}
+		installer.extracted = j.Extracted
+		installer.manifest = manifest
+	case StageInstall:
+		return fileExists(filepath.Join(installer.rootDir, "bin", "zowe-configure-instance.sh"))
+	case StageConfigure:
+		return fileExists(filepath.Join(installer.instanceDir, "instance.env"))
+	}
+	return nil
+}
+
+// recordStage remembers a completed stage together with the digest of its output.
+func (installer *ZoweInstaller) recordStage(stage Stage) error {
+	j := installer.journal
+	if stage == StageDownload {
+		digest, err := fileDigest(installer.paxFileName)
+		if err != nil {
+			return err
+		}
+		j.Digests[installer.paxFileName] = digest
+	}
+	return j.complete(stage)
+}
@@ -0,0 +1,75 @@
+package installer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestZoweInstaller_loadJournal(t *testing.T) {
+	tests := []struct {
+		name      string
+		source    string
+		changePax bool
+		want      []Stage
+		wantNil   bool
+	}{
+		{"same installation", "zowe.pax", false, []Stage{StageDownload, StageVerify}, false},
+		{"different source", "other.pax", false, nil, true},
+		{"changed pax", "zowe.pax", true, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home, err := ioutil.TempDir("", "journal")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(home)
+			pax := filepath.Join(home, "zowe.pax")
+			ioutil.WriteFile(pax, []byte("pax"), 0644)
+			installer := New()
+			installer.WorkDir = filepath.Join(home, "zowe")
+			if err := installer.resolve(pax); err != nil {
+				t.Fatal(err)
+			}
+			os.MkdirAll(installer.dir, 0755)
+			ioutil.WriteFile(installer.paxFileName, []byte("pax"), 0644)
+			installer.journal = installer.newJournal()
+			for _, stage := range []Stage{StageDownload, StageVerify} {
+				if err := installer.recordStage(stage); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.changePax {
+				ioutil.WriteFile(installer.paxFileName, []byte("changed"), 0644)
+			}
+			if err := installer.resolve(filepath.Join(home, tt.source)); err != nil {
+				t.Fatal(err)
+			}
+			installer.journal = installer.loadJournal()
+			if (installer.journal == nil) != tt.wantNil {
+				t.Fatalf("loadJournal() = %v, wantNil %v", installer.journal, tt.wantNil)
+			}
+			if tt.wantNil {
+				return
+			}
+			err = installer.verifyStage(StageDownload)
+			if (err != nil) != tt.changePax {
+				t.Errorf("verifyStage(download) error = %v, want error %v", err, tt.changePax)
+			}
+			for _, stage := range tt.want {
+				if !installer.journal.completed(stage) {
+					t.Errorf("stage %s is not completed", stage)
+				}
+			}
+			installer.journal.reset(StageVerify)
+			if installer.journal.completed(StageVerify) || !installer.journal.completed(StageDownload) {
+				t.Errorf("reset(verify) left stages %v", installer.journal.Stages)
+			}
+			if err := installer.verifyStage(StageExtract); err == nil {
+				t.Errorf("verifyStage(extract) accepted a journal without the extracted directory")
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package installer
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const manifestFile = "manifest.json"
+
+// Manifest describes a Zowe build. It is read from the manifest.json in the
+// top-level directory of the PAX.
+type Manifest struct {
+	Name       string      `json:"name"`
+	Version    string      `json:"version"`
+	Build      BuildInfo   `json:"build"`
+	Components []Component `json:"components"`
+}
+
+// BuildInfo identifies the build a PAX was made by.
+type BuildInfo struct {
+	Branch     string `json:"branch,omitempty"`
+	Number     string `json:"number,omitempty"`
+	CommitHash string `json:"commitHash,omitempty"`
+	Timestamp  string `json:"timestamp,omitempty"`
+}
+
+// Component is a component bundled in the PAX.
+type Component struct {
+	ID      string `json:"id"`
+	Version string `json:"version"`
+}
+
+// readManifest reads the manifest.json in dir. The components are taken from
+// its binaryDependencies.
+func readManifest(dir string) (*Manifest, error) {
+	file := filepath.Join(dir, manifestFile)
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read PAX manifest")
+	}
+	var raw struct {
+		Name               string    `json:"name"`
+		Version            string    `json:"version"`
+		Build              BuildInfo `json:"build"`
+		BinaryDependencies map[string]struct {
+			Version string `json:"version"`
+		} `json:"binaryDependencies"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse PAX manifest %s", file)
+	}
+	if raw.Version == "" {
+		return nil, errors.Errorf("PAX manifest %s has no version", file)
+	}
+	manifest := &Manifest{Name: raw.Name, Version: raw.Version, Build: raw.Build}
+	for id, dependency := range raw.BinaryDependencies {
+		manifest.Components = append(manifest.Components, Component{ID: id, Version: dependency.Version})
+	}
+	sort.Slice(manifest.Components, func(i, j int) bool {
+		return manifest.Components[i].ID < manifest.Components[j].ID
+	})
+	return manifest, nil
+}
+
+// topLevelName returns the first element of an archive entry name.
+func topLevelName(name string) string {
+	name = strings.TrimPrefix(filepath.ToSlash(filepath.Clean(name)), "./")
+	if i := strings.Index(name, "/"); i >= 0 {
+		return name[:i]
+	}
+	return name
+}
+
+// extractedRoot checks the layout of a PAX extracted into workDir with the
+// given top-level entries: a single directory with manifest.json and the
+// install directory. It returns that directory and its manifest.
+func extractedRoot(workDir string, topLevel map[string]bool) (string, *Manifest, error) {
+	var names []string
+	for name := range topLevel {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if len(names) != 1 {
+		return "", nil, errors.Errorf("unrecognized PAX layout: expected a single top-level directory, found %s", strings.Join(names, ", "))
+	}
+	dir := filepath.Join(workDir, names[0])
+	if fi, err := os.Stat(dir); err != nil || !fi.IsDir() {
+		return "", nil, errors.Errorf("unrecognized PAX layout: top-level entry %s is not a directory", names[0])
+	}
+	manifest, err := readManifest(dir)
+	if err != nil {
+		return "", nil, errors.Wrapf(err, "unrecognized PAX layout")
+	}
+	if fi, err := os.Stat(filepath.Join(dir, "install")); err != nil || !fi.IsDir() {
+		return "", nil, errors.Errorf("unrecognized PAX layout: %s has no install directory", names[0])
+	}
+	return dir, manifest, nil
+}
+
+// extractedDir is the top-level directory of the extracted PAX, empty if it
+// isn't extracted yet.
+func (installer *ZoweInstaller) extractedDir() string {
+	if installer.extracted == "" && installer.journal != nil {
+		return installer.journal.Extracted
+	}
+	return installer.extracted
+}
+
+// installDir is the install directory of the extracted PAX.
+func (installer *ZoweInstaller) installDir() (string, error) {
+	dir := installer.extractedDir()
+	if dir == "" {
+		return "", errors.New("the PAX is not extracted")
+	}
+	return filepath.Join(dir, "install"), nil
+}
+
+// Manifest returns the manifest of the extracted PAX, nil before ExtractPax.
+func (installer *ZoweInstaller) Manifest() *Manifest {
+	return installer.manifest
+}
@@ -0,0 +1,120 @@
+package installer
+
+import (
+	"archive/tar"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testManifest = `{
+  "name": "Zowe",
+  "version": "1.25.0",
+  "build": {"branch": "v1.x/master", "number": "1234", "commitHash": "abc123", "timestamp": "1633000000000"},
+  "binaryDependencies": {
+    "org.zowe.zlux.zlux-core": {"version": "~1.25.0-RC"},
+    "org.zowe.explorer-jes": {"version": "~1.0.0"}
+  }
+}`
+
+func writePax(t *testing.T, file string, files map[string]string) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for name, body := range files {
+		hdr := &tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(body))}
+		if strings.HasSuffix(name, "/") {
+			hdr.Typeflag = tar.TypeDir
+			hdr.Mode = 0755
+		}
+		if name == "pax_global_header" {
+			hdr = &tar.Header{Name: name, Typeflag: tar.TypeXGlobalHeader, PAXRecords: map[string]string{"comment": body}}
+			body = ""
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write([]byte(body))
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(file, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestZoweInstaller_ExtractPax(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantDir string
+		wantErr string
+	}{
+		{"renamed build", map[string]string{
+			"zowe-1.25.0/":                        "",
+			"zowe-1.25.0/manifest.json":           testManifest,
+			"zowe-1.25.0/install/zowe-install.sh": "#!/bin/sh\n",
+		}, "zowe-1.25.0", ""},
+		{"global header", map[string]string{
+			"pax_global_header":                   "git commit abc123",
+			"zowe-1.25.0/manifest.json":           testManifest,
+			"zowe-1.25.0/install/zowe-install.sh": "#!/bin/sh\n",
+		}, "zowe-1.25.0", ""},
+		{"several top-level entries", map[string]string{
+			"zowe-1.25.0/manifest.json": testManifest,
+			"README":                    "",
+		}, "", "expected a single top-level directory, found README, zowe-1.25.0"},
+		{"no manifest", map[string]string{
+			"zowe-1.25.0/install/zowe-install.sh": "#!/bin/sh\n",
+		}, "", "failed to read PAX manifest"},
+		{"no version", map[string]string{
+			"zowe-1.25.0/manifest.json":           `{"name": "Zowe"}`,
+			"zowe-1.25.0/install/zowe-install.sh": "#!/bin/sh\n",
+		}, "", "has no version"},
+		{"no install dir", map[string]string{
+			"zowe-1.25.0/manifest.json": testManifest,
+		}, "", "has no install directory"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home, err := ioutil.TempDir("", "extract")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(home)
+			installer := New()
+			installer.Progress = QuietReporter{}
+			installer.WorkDir = home
+			if err := installer.resolve(filepath.Join(home, "site-build.pax")); err != nil {
+				t.Fatal(err)
+			}
+			writePax(t, installer.paxFileName, tt.files)
+			err = installer.ExtractPax()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ExtractPax() error = %v, want %q", err, tt.wantErr)
+				}
+				if !strings.Contains(err.Error(), "unrecognized PAX layout") {
+					t.Errorf("ExtractPax() error = %v doesn't name the layout", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ExtractPax() error = %v", err)
+			}
+			installDir, err := installer.installDir()
+			if want := filepath.Join(home, tt.wantDir, "install"); err != nil || installDir != want {
+				t.Errorf("installDir() = %s, %v, want %s", installDir, err, want)
+			}
+			manifest := installer.Manifest()
+			wantComponents := []Component{{"org.zowe.explorer-jes", "~1.0.0"}, {"org.zowe.zlux.zlux-core", "~1.25.0-RC"}}
+			if manifest.Version != "1.25.0" || manifest.Build.Number != "1234" || !reflect.DeepEqual(manifest.Components, wantComponents) {
+				t.Errorf("Manifest() = %+v", manifest)
+			}
+		})
+	}
+}
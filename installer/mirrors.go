@@ -0,0 +1,90 @@
+package installer
+
+import (
+	"bufio"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// readMirrorsFile reads a mirror list, one URL per line. Empty lines and lines
+// starting with # are ignored.
+func readMirrorsFile(file string) ([]string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open mirrors file")
+	}
+	defer f.Close()
+	var mirrors []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		mirrors = append(mirrors, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrapf(err, "failed to read mirrors file %s", file)
+	}
+	return mirrors, nil
+}
+
+// sources returns the URLs to download the PAX from in turn: the PAX URL
+// followed by the mirrors. A mirror ending with / is a directory the PAX name is
+// appended to, otherwise it is the URL of the PAX itself.
+func (installer *ZoweInstaller) sources() ([]string, error) {
+	if !isRemote(installer.paxURL) {
+		return []string{installer.paxURL}, nil
+	}
+	mirrors := installer.Mirrors
+	if installer.MirrorsFile != "" {
+		fileMirrors, err := readMirrorsFile(installer.MirrorsFile)
+		if err != nil {
+			return nil, err
+		}
+		mirrors = append(append([]string(nil), mirrors...), fileMirrors...)
+	}
+	sources := []string{installer.paxURL}
+	for _, mirror := range mirrors {
+		mirror = installer.withoutCredentials(mirror)
+		if !isRemote(mirror) {
+			return nil, errors.Errorf("mirror %s is not an HTTP(S) URL", mirror)
+		}
+		if strings.HasSuffix(mirror, "/") {
+			mirror += filepath.Base(installer.paxFileName)
+		}
+		if mirror != installer.paxURL {
+			sources = append(sources, mirror)
+		}
+	}
+	return sources, nil
+}
+
+// fromSources calls fn with the sources in turn until it succeeds. It returns
+// the source that succeeded or the last error.
+func (installer *ZoweInstaller) fromSources(what string, fn func(source string) error) (string, error) {
+	sources, err := installer.sources()
+	if err != nil {
+		return "", err
+	}
+	for i, source := range sources {
+		if i > 0 {
+			log.Printf("Trying mirror %s", source)
+		}
+		err = fn(source)
+		if err == nil {
+			return source, nil
+		}
+		if len(sources) > 1 {
+			log.Printf("failed to get %s from %s: %v", what, source, err)
+		}
+	}
+	if len(sources) > 1 {
+		return "", errors.Wrapf(err, "failed to get %s from all %d sources", what, len(sources))
+	}
+	return "", err
+}
@@ -0,0 +1,72 @@
+package installer
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/hex"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestZoweInstaller_DownloadPaxMirrors(t *testing.T) {
+	content := strings.Repeat("zowe pax content ", 1000)
+	sum := sha512.Sum512([]byte(content))
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/blocked/"):
+			w.WriteHeader(http.StatusForbidden)
+		case strings.HasPrefix(r.URL.Path, "/tampered/"):
+			w.Write([]byte(strings.ToUpper(content)))
+		case strings.HasPrefix(r.URL.Path, "/mirror/"):
+			w.Write([]byte(content))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	dir, err := ioutil.TempDir("", "mirrors")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	mirrorsFile := filepath.Join(dir, "mirrors")
+	ioutil.WriteFile(mirrorsFile, []byte("# internal mirror\n\n"+server.URL+"/mirror/\n"), 0644)
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+	installer := New()
+	installer.Progress = QuietReporter{}
+	installer.Retries = 0
+	installer.Checksum = "sha512:" + hex.EncodeToString(sum[:])
+	installer.Mirrors = []string{server.URL + "/tampered/zowe-1.25.0.pax"}
+	installer.MirrorsFile = mirrorsFile
+	installer.WorkDir = dir
+	if err := installer.resolve(server.URL + "/blocked/zowe-1.25.0.pax"); err != nil {
+		t.Fatal(err)
+	}
+	if err := installer.resolveChecksum(); err != nil {
+		t.Fatal(err)
+	}
+	if err := installer.DownloadPax(); err != nil {
+		t.Fatalf("DownloadPax() error = %v", err)
+	}
+	want := []string{"/blocked/zowe-1.25.0.pax", "/tampered/zowe-1.25.0.pax", "/mirror/zowe-1.25.0.pax"}
+	if strings.Join(requests, " ") != strings.Join(want, " ") {
+		t.Errorf("requests = %v, want %v", requests, want)
+	}
+	data, _ := ioutil.ReadFile(installer.paxFileName)
+	if string(data) != content {
+		t.Errorf("downloaded the wrong PAX")
+	}
+	if !strings.Contains(logs.String(), "from "+server.URL+"/mirror/zowe-1.25.0.pax") {
+		t.Errorf("log doesn't name the mirror:\n%s", logs.String())
+	}
+}
@@ -0,0 +1,312 @@
+package installer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	humanize "github.com/dustin/go-humanize"
+	"github.com/pkg/errors"
+)
+
+// Plan describes what Install would do for a PAX.
+type Plan struct {
+	Source  string   `json:"source"`
+	Mirrors []string `json:"mirrors,omitempty"`
+	PaxFile string   `json:"paxFile"`
+	// Size of the PAX in bytes, 0 when unknown
+	Size        int64            `json:"size,omitempty"`
+	Checksum    string           `json:"checksum,omitempty"`
+	Signature   string           `json:"signature,omitempty"`
+	WorkDir     string           `json:"workDir"`
+	RootDir     string           `json:"rootDir"`
+	InstanceDir string           `json:"instanceDir"`
+	LogDir      string           `json:"logDir,omitempty"`
+	User        string           `json:"user"`
+	Group       string           `json:"group"`
+	Steps       []Stage          `json:"steps"`
+	Commands    []PlannedCommand `json:"commands"`
+	Checks      []PlanCheck      `json:"checks"`
+}
+
+// PlannedCommand is a script Install would run.
+type PlannedCommand struct {
+	Dir  string   `json:"dir"`
+	Args []string `json:"args"`
+}
+
+// PlanCheck is the outcome of a precondition check.
+type PlanCheck struct {
+	Name    string `json:"name"`
+	OK      bool   `json:"ok"`
+	Message string `json:"message"`
+}
+
+// OK reports whether all preconditions are met.
+func (plan *Plan) OK() bool {
+	for _, check := range plan.Checks {
+		if !check.OK {
+			return false
+		}
+	}
+	return true
+}
+
+func (plan *Plan) addCheck(name string, err error, message string) {
+	check := PlanCheck{Name: name, OK: err == nil, Message: message}
+	if err != nil {
+		check.Message = err.Error()
+	}
+	plan.Checks = append(plan.Checks, check)
+}
+
+// Plan resolves everything Install would do for the PAX at paxURL and checks
+// the preconditions, without downloading or changing anything.
+func (installer *ZoweInstaller) Plan(paxURL string) (*Plan, error) {
+	if err := installer.resolve(paxURL); err != nil {
+		return nil, err
+	}
+	plan := &Plan{
+		Source:      installer.paxURL,
+		PaxFile:     installer.paxFileName,
+		WorkDir:     installer.dir,
+		RootDir:     installer.rootDir,
+		InstanceDir: installer.instanceDir,
+		LogDir:      installer.logDir,
+	}
+	steps, err := installer.pipeline()
+	if err != nil {
+		plan.addCheck("hooks", err, "")
+	}
+	for _, step := range steps {
+		plan.Steps = append(plan.Steps, step.Name)
+	}
+	installer.planSource(plan)
+	installer.planVerification(plan)
+	installer.planTargets(plan)
+	plan.User, err = installer.installUser()
+	plan.addCheck("user", err, plan.User)
+	plan.Group, err = installer.instanceGroup()
+	plan.addCheck("group", err, plan.Group)
+	commands := []func(string) (*exec.Cmd, error){installer.installCommand, installer.configureCommand}
+	for i, dir := range []string{installer.rootDir, installer.instanceDir} {
+		cmd, err := commands[i](dir)
+		if err != nil {
+			plan.addCheck("command", err, "")
+			continue
+		}
+		if cmd.Dir == "" {
+			// the top-level directory is only known once the PAX is extracted
+			cmd.Dir = filepath.Join(installer.dir, "<PAX directory>", "install")
+		}
+		plan.Commands = append(plan.Commands, PlannedCommand{Dir: cmd.Dir, Args: cmd.Args})
+	}
+	for _, extension := range installer.Extensions {
+		cmd, err := installer.extensionCommand(extension)
+		if err != nil {
+			plan.addCheck("command", err, "")
+			continue
+		}
+		plan.Commands = append(plan.Commands, PlannedCommand{Dir: cmd.Dir, Args: cmd.Args})
+	}
+	plan.addCheck("instance", installer.checkInstanceConfig(), fmt.Sprintf("%d extensions, %d instance.env overrides", len(installer.Extensions), len(installer.InstanceEnv)))
+	return plan, nil
+}
+
+func (installer *ZoweInstaller) planSource(plan *Plan) {
+	if installer.paxURL == Stdin {
+		plan.addCheck("source", nil, "PAX will be read from stdin")
+		return
+	}
+	if !isRemote(installer.paxURL) {
+		file, err := localPath(installer.paxURL)
+		if err == nil {
+			var fi os.FileInfo
+			if fi, err = os.Stat(file); err == nil {
+				plan.Size = fi.Size()
+			}
+		}
+		plan.addCheck("source", err, fmt.Sprintf("%s exists, %s", installer.paxURL, humanize.Bytes(uint64(plan.Size))))
+		return
+	}
+	sources, err := installer.sources()
+	if err != nil {
+		plan.addCheck("source", err, "")
+		return
+	}
+	plan.Mirrors = sources[1:]
+	source, err := installer.fromSources("PAX", func(source string) error {
+		size, err := installer.headSource(source)
+		if size > 0 {
+			plan.Size = size
+		}
+		return err
+	})
+	if err != nil {
+		plan.addCheck("source", err, "")
+		return
+	}
+	size := "unknown size"
+	if plan.Size > 0 {
+		size = humanize.Bytes(uint64(plan.Size))
+	}
+	plan.addCheck("source", nil, fmt.Sprintf("%s is reachable, %s", source, size))
+}
+
+// headSource returns the size of the PAX at url, -1 if unknown.
+func (installer *ZoweInstaller) headSource(url string) (int64, error) {
+	resp, err := installer.head(url)
+	if err != nil {
+		return 0, err
+	}
+	return resp.ContentLength, nil
+}
+
+// head sends a HEAD request for url. The body of the response is closed.
+func (installer *ZoweInstaller) head(url string) (*http.Response, error) {
+	ctx, cancel := installer.withTimeout(context.Background())
+	defer cancel()
+	req, err := installer.newRequest(ctx, http.MethodHead, url)
+	if err != nil {
+		return nil, err
+	}
+	client, err := installer.httpClient()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to reach %s", url)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, &StatusError{URL: url, StatusCode: resp.StatusCode}
+	}
+	return resp, nil
+}
+
+func (installer *ZoweInstaller) planVerification(plan *Plan) {
+	if installer.Checksum != "" {
+		checksum, err := ParseChecksum(installer.Checksum)
+		if err == nil {
+			plan.Checksum = checksum.String()
+		}
+		plan.addCheck("checksum", err, "PAX will be verified against "+plan.Checksum)
+	} else if installer.VerifySidecar && installer.paxURL == Stdin {
+		plan.addCheck("checksum", errStdinSidecar, "")
+	} else if installer.VerifySidecar {
+		plan.Checksum = installer.paxURL + "." + SHA512
+		plan.addCheck("checksum", nil, "PAX will be verified against "+plan.Checksum)
+	}
+	if installer.Keyring != "" {
+		plan.Signature = installer.withoutCredentials(installer.SignatureURL)
+		if plan.Signature == "" {
+			plan.Signature = installer.paxURL + ".asc"
+		}
+		_, err := readKeyring(installer.Keyring)
+		plan.addCheck("signature", err, fmt.Sprintf("signature %s will be checked against %s", plan.Signature, installer.Keyring))
+	}
+}
+
+func (installer *ZoweInstaller) planTargets(plan *Plan) {
+	keep := installer.keptFiles()
+	for _, dir := range installer.targetDirs() {
+		if dir == installer.instanceDir && installer.keepsInstance() {
+			plan.addCheck("target "+dir, nil, "the configured instance will be kept for the new version")
+			continue
+		}
+		entries, err := readDirExcept(dir, keep)
+		if err != nil {
+			plan.addCheck("target "+dir, err, "")
+			continue
+		}
+		if len(entries) > 0 {
+			switch {
+			case installer.Backup:
+				plan.addCheck("target "+dir, nil, fmt.Sprintf("%d existing entries will be moved to a backup", len(entries)))
+			case installer.Force && dir == installer.dir && !createdWorkDir(dir):
+				plan.addCheck("target "+dir, notCreatedError(dir), "")
+			case installer.Force:
+				plan.addCheck("target "+dir, nil, fmt.Sprintf("%d existing entries will be deleted", len(entries)))
+			default:
+				plan.addCheck("target "+dir, &ExistingInstallError{Dirs: []string{dir}}, "")
+			}
+			continue
+		}
+		ancestor := existingAncestor(dir)
+		err = checkWritable(ancestor)
+		if err != nil {
+			err = errors.Wrapf(err, "%s is not writable", ancestor)
+		}
+		plan.addCheck("target "+dir, err, "writable")
+	}
+}
+
+// existingAncestor returns dir or its nearest parent that exists.
+func existingAncestor(dir string) string {
+	for {
+		if _, err := os.Stat(dir); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dir
+		}
+		dir = parent
+	}
+}
+
+// WriteText prints the plan for humans.
+func (plan *Plan) WriteText(w io.Writer) {
+	fmt.Fprintf(w, "Source:       %s\n", plan.Source)
+	for _, mirror := range plan.Mirrors {
+		fmt.Fprintf(w, "Mirror:       %s\n", mirror)
+	}
+	fmt.Fprintf(w, "PAX file:     %s\n", plan.PaxFile)
+	if plan.Checksum != "" {
+		fmt.Fprintf(w, "Checksum:     %s\n", plan.Checksum)
+	}
+	if plan.Signature != "" {
+		fmt.Fprintf(w, "Signature:    %s\n", plan.Signature)
+	}
+	fmt.Fprintf(w, "Work dir:     %s\n", plan.WorkDir)
+	fmt.Fprintf(w, "ROOT_DIR:     %s\n", plan.RootDir)
+	fmt.Fprintf(w, "INSTANCE_DIR: %s\n", plan.InstanceDir)
+	if plan.LogDir != "" {
+		fmt.Fprintf(w, "Log dir:      %s\n", plan.LogDir)
+	}
+	fmt.Fprintf(w, "User:         %s\n", plan.User)
+	fmt.Fprintf(w, "Group:        %s\n", plan.Group)
+	fmt.Fprintln(w, "Steps:")
+	for _, step := range plan.Steps {
+		fmt.Fprintf(w, "  %s\n", step)
+	}
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range plan.Commands {
+		fmt.Fprintf(w, "  (cd %s && %s)\n", cmd.Dir, strings.Join(cmd.Args, " "))
+	}
+	fmt.Fprintln(w, "Checks:")
+	for _, check := range plan.Checks {
+		status := "OK  "
+		if !check.OK {
+			status = "FAIL"
+		}
+		fmt.Fprintf(w, "  [%s] %s: %s\n", status, check.Name, check.Message)
+	}
+}
+
+// WriteJSON prints the plan as indented JSON.
+func (plan *Plan) WriteJSON(w io.Writer) error {
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
@@ -0,0 +1,53 @@
+package installer
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestZoweInstaller_Plan(t *testing.T) {
+	home, err := ioutil.TempDir("", "plan")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+	pax := filepath.Join(home, "zowe-1.25.0.pax")
+	if err := ioutil.WriteFile(pax, []byte("pax"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	installer := New()
+	installer.WorkDir = filepath.Join(home, "work")
+	plan, err := installer.Plan("file://" + filepath.ToSlash(pax))
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	if !plan.OK() {
+		t.Errorf("Plan() checks failed: %+v", plan.Checks)
+	}
+	if plan.Size != 3 {
+		t.Errorf("Plan().Size = %d, want 3", plan.Size)
+	}
+	if len(plan.Commands) != 2 || plan.Commands[0].Args[0] != "./zowe-install.sh" {
+		t.Errorf("Plan().Commands = %+v", plan.Commands)
+	}
+	if _, err := os.Stat(installer.WorkDir); !os.IsNotExist(err) {
+		t.Errorf("Plan() created %s", installer.WorkDir)
+	}
+	installer.VerifySidecar = true
+	installer.PaxName = "zowe-1.25.0.pax"
+	plan, err = installer.Plan(Stdin)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	if plan.OK() {
+		t.Errorf("Plan() accepted a checksum file for a PAX read from stdin")
+	}
+	data, _ := json.Marshal(plan)
+	if strings.Contains(string(data), `"size"`) {
+		t.Errorf("Plan() of unknown size = %s", data)
+	}
+}
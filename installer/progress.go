@@ -0,0 +1,218 @@
+package installer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	humanize "github.com/dustin/go-humanize"
+)
+
+type Stage string
+
+const (
+	StagePrepare   Stage = "prepare"
+	StageDownload  Stage = "download"
+	StageVerify    Stage = "verify"
+	StageExtract   Stage = "extract"
+	StageInstall   Stage = "install"
+	StageConfigure Stage = "configure"
+)
+
+type ProgressState string
+
+const (
+	ProgressStarted ProgressState = "started"
+	ProgressRunning ProgressState = "running"
+	ProgressDone    ProgressState = "done"
+	ProgressFailed  ProgressState = "failed"
+)
+
+const progressInterval = 200 * time.Millisecond
+
+// ProgressEvent reports the state of an install stage. Done and Total count
+// bytes, Total is -1 when unknown. Rate is in bytes per second, in JSON the
+// ETA is in seconds too.
+type ProgressEvent struct {
+	Time    time.Time     `json:"time"`
+	Stage   Stage         `json:"stage"`
+	State   ProgressState `json:"state"`
+	Done    int64         `json:"done"`
+	Total   int64         `json:"total"`
+	Rate    float64       `json:"rate"`
+	ETA     time.Duration `json:"eta"`
+	Message string        `json:"message,omitempty"`
+}
+
+// MarshalJSON writes the ETA in seconds rather than nanoseconds.
+func (event ProgressEvent) MarshalJSON() ([]byte, error) {
+	type plain ProgressEvent
+	return json.Marshal(struct {
+		plain
+		ETA float64 `json:"eta"`
+	}{plain(event), event.ETA.Seconds()})
+}
+
+// ProgressReporter receives progress events of all install stages.
+type ProgressReporter interface {
+	Report(event ProgressEvent)
+}
+
+// TerminalReporter prints progress on a single updating line.
+type TerminalReporter struct {
+	Writer io.Writer
+	width  int
+}
+
+func NewTerminalReporter(w io.Writer) *TerminalReporter {
+	return &TerminalReporter{Writer: w}
+}
+
+var stageNames = map[Stage]string{
+	StagePrepare:   "Preparing",
+	StageDownload:  "Downloading",
+	StageVerify:    "Verifying",
+	StageExtract:   "Extracting",
+	StageInstall:   "Installing",
+	StageConfigure: "Configuring instance",
+}
+
+func (r *TerminalReporter) Report(event ProgressEvent) {
+	name, ok := stageNames[event.Stage]
+	if !ok {
+		name = "Running " + string(event.Stage)
+	}
+	switch event.State {
+	case ProgressStarted:
+		fmt.Fprintf(r.Writer, "%s...\n", name)
+	case ProgressRunning:
+		line := fmt.Sprintf("%s... %s", name, humanize.Bytes(uint64(event.Done)))
+		if event.Total > 0 {
+			line += fmt.Sprintf(" of %s (%d%%)", humanize.Bytes(uint64(event.Total)), event.Done*100/event.Total)
+		}
+		if event.Rate > 0 {
+			line += fmt.Sprintf(", %s/s", humanize.Bytes(uint64(event.Rate)))
+		}
+		if event.ETA > 0 {
+			line += fmt.Sprintf(", ETA %s", event.ETA.Round(time.Second))
+		}
+		padding := ""
+		if len(line) < r.width {
+			padding = strings.Repeat(" ", r.width-len(line))
+		}
+		r.width = len(line)
+		fmt.Fprintf(r.Writer, "\r%s%s", line, padding)
+	case ProgressDone:
+		r.endLine()
+		fmt.Fprintf(r.Writer, "%s done\n", name)
+	case ProgressFailed:
+		r.endLine()
+		fmt.Fprintf(r.Writer, "%s failed: %s\n", name, event.Message)
+	}
+}
+
+func (r *TerminalReporter) endLine() {
+	if r.width > 0 {
+		fmt.Fprintln(r.Writer)
+		r.width = 0
+	}
+}
+
+// QuietReporter discards all progress events.
+type QuietReporter struct{}
+
+func (QuietReporter) Report(event ProgressEvent) {}
+
+// JSONReporter writes every progress event as a line of JSON.
+type JSONReporter struct {
+	Writer io.Writer
+	mutex  sync.Mutex
+}
+
+func NewJSONReporter(w io.Writer) *JSONReporter {
+	return &JSONReporter{Writer: w}
+}
+
+func (r *JSONReporter) Report(event ProgressEvent) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	fmt.Fprintf(r.Writer, "%s\n", data)
+}
+
+// progress tracks a single stage and sends throttled events to a reporter. It is
+// an io.Writer that counts the bytes written to it.
+type progress struct {
+	reporter ProgressReporter
+	stage    Stage
+	start    time.Time
+	offset   int64
+	done     int64
+	total    int64
+	last     time.Time
+}
+
+func (installer *ZoweInstaller) startProgress(stage Stage, offset int64, total int64) *progress {
+	reporter := installer.Progress
+	if reporter == nil {
+		reporter = QuietReporter{}
+	}
+	p := &progress{
+		reporter: reporter,
+		stage:    stage,
+		start:    time.Now(),
+		offset:   offset,
+		done:     offset,
+		total:    total,
+	}
+	p.report(ProgressStarted, "")
+	return p
+}
+
+func (p *progress) Write(data []byte) (int, error) {
+	p.add(int64(len(data)))
+	return len(data), nil
+}
+
+func (p *progress) add(n int64) {
+	p.done += n
+	if time.Since(p.last) >= progressInterval {
+		p.report(ProgressRunning, "")
+	}
+}
+
+// finish reports the stage as done or failed with err and returns err.
+func (p *progress) finish(err error) error {
+	if err != nil {
+		p.report(ProgressFailed, err.Error())
+	} else {
+		p.report(ProgressDone, "")
+	}
+	return err
+}
+
+func (p *progress) report(state ProgressState, message string) {
+	now := time.Now()
+	p.last = now
+	event := ProgressEvent{
+		Time:    now,
+		Stage:   p.stage,
+		State:   state,
+		Done:    p.done,
+		Total:   p.total,
+		Message: message,
+	}
+	if elapsed := now.Sub(p.start).Seconds(); elapsed > 0 {
+		event.Rate = float64(p.done-p.offset) / elapsed
+	}
+	if event.Rate > 0 && p.total > p.done {
+		event.ETA = time.Duration(float64(p.total-p.done) / event.Rate * float64(time.Second))
+	}
+	p.reporter.Report(event)
+}
@@ -0,0 +1,65 @@
+package installer
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type recordingReporter struct {
+	events []ProgressEvent
+}
+
+func (r *recordingReporter) Report(event ProgressEvent) {
+	r.events = append(r.events, event)
+}
+
+func TestZoweInstaller_progress(t *testing.T) {
+	dir, err := ioutil.TempDir("", "progress")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	content := strings.Repeat("zowe", 1000)
+	source := filepath.Join(dir, "source.pax")
+	if err := ioutil.WriteFile(source, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	reporter := &recordingReporter{}
+	installer := New()
+	installer.Progress = reporter
+	installer.paxURL = source
+	installer.paxFileName = filepath.Join(dir, "zowe.pax")
+	if err := installer.DownloadPax(); err != nil {
+		t.Fatalf("DownloadPax() error = %v", err)
+	}
+	if len(reporter.events) < 2 {
+		t.Fatalf("got %d events, want at least 2", len(reporter.events))
+	}
+	first := reporter.events[0]
+	last := reporter.events[len(reporter.events)-1]
+	if first.Stage != StageDownload || first.State != ProgressStarted {
+		t.Errorf("first event = %s %s, want download started", first.Stage, first.State)
+	}
+	if last.State != ProgressDone || last.Done != int64(len(content)) || last.Total != int64(len(content)) {
+		t.Errorf("last event = %s %d/%d, want done %d/%d", last.State, last.Done, last.Total, len(content), len(content))
+	}
+}
+
+func TestJSONReporter_Report(t *testing.T) {
+	var out bytes.Buffer
+	reporter := NewJSONReporter(&out)
+	reporter.Report(ProgressEvent{Stage: StageDownload, State: ProgressRunning, Done: 512, Total: 1024, Rate: 256, ETA: 2500 * time.Millisecond})
+	var event map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &event); err != nil {
+		t.Fatalf("Report() wrote %q: %v", out.String(), err)
+	}
+	if event["eta"] != 2.5 || event["stage"] != "download" || event["done"] != 512.0 {
+		t.Errorf("Report() wrote %q, want the ETA in seconds", out.String())
+	}
+}
@@ -0,0 +1,215 @@
+package installer
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	humanize "github.com/dustin/go-humanize"
+	"github.com/lchudinov/zowe_installer/launcher"
+	"github.com/pkg/errors"
+)
+
+// PrunePolicy selects the versions Prune keeps. A version is kept when it is
+// one of the Keep most recently installed ones or was installed within KeepDays.
+// The current version and versions a launcher runs from are always kept.
+type PrunePolicy struct {
+	Keep     int
+	KeepDays int
+	// CacheDir is the download cache the PAX files of the removed versions are
+	// removed from, unless a kept version came from the same URL.
+	CacheDir string
+	// DryRun reports what would be removed without removing it.
+	DryRun bool
+}
+
+// PruneReport lists what Prune kept and removed.
+type PruneReport struct {
+	Kept      []KeptVersion `json:"kept"`
+	Removed   []RemovedDir  `json:"removed"`
+	Skipped   []SkippedDir  `json:"skipped,omitempty"`
+	Reclaimed int64         `json:"reclaimed"`
+	DryRun    bool          `json:"dryRun"`
+}
+
+// KeptVersion is a version Prune kept and why.
+type KeptVersion struct {
+	Version string `json:"version"`
+	Reason  string `json:"reason"`
+}
+
+// RemovedDir is a directory Prune removed.
+type RemovedDir struct {
+	Dir  string `json:"dir"`
+	Size int64  `json:"size"`
+}
+
+// SkippedDir is a directory of a removed version Prune left alone and why.
+type SkippedDir struct {
+	Dir    string `json:"dir"`
+	Reason string `json:"reason"`
+}
+
+// Prune removes the versions installed in versionsDir that policy doesn't keep,
+// together with their backups, the work directories the installer created for
+// them and their PAX files in the cache.
+func Prune(versionsDir string, policy PrunePolicy) (*PruneReport, error) {
+	versions, err := ListVersions(versionsDir)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(versions, func(i, j int) bool {
+		return versions[i].Installed.After(versions[j].Installed)
+	})
+	report := &PruneReport{DryRun: policy.DryRun}
+	var kept, pruned []Version
+	for i, version := range versions {
+		reason := keepReason(version, i, policy)
+		if reason == "" {
+			pruned = append(pruned, version)
+			continue
+		}
+		kept = append(kept, version)
+		report.Kept = append(report.Kept, KeptVersion{Version: version.Version, Reason: reason})
+	}
+	for _, version := range pruned {
+		dirs := []string{version.Dir}
+		backups, _ := filepath.Glob(version.Dir + ".bak-*")
+		dirs = append(dirs, backups...)
+		if workDir := version.info.WorkDir; workDir != "" && !inUse(workDir, versionsDir, kept, version) {
+			if createdWorkDir(workDir) {
+				dirs = append(dirs, workDir)
+			} else if _, err := os.Stat(workDir); err == nil {
+				report.Skipped = append(report.Skipped, SkippedDir{Dir: workDir, Reason: "not created by the installer"})
+			}
+		}
+		for _, dir := range dirs {
+			if err := report.remove(dir, policy.DryRun); err != nil {
+				return report, err
+			}
+		}
+	}
+	if policy.CacheDir != "" {
+		if err := pruneCache(NewCache(policy.CacheDir), kept, pruned, report); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// remove removes path unless the report is for a dry run and records it.
+func (report *PruneReport) remove(path string, dryRun bool) error {
+	if _, err := os.Lstat(path); err != nil {
+		return nil
+	}
+	removed := RemovedDir{Dir: path, Size: dirSize(path)}
+	if !dryRun {
+		if err := os.RemoveAll(path); err != nil {
+			return errors.Wrapf(err, "failed to remove %s", path)
+		}
+	}
+	report.Removed = append(report.Removed, removed)
+	report.Reclaimed += removed.Size
+	return nil
+}
+
+// pruneCache removes the cache entries of the URLs the pruned versions were
+// installed from, and their files unless another entry refers to them.
+func pruneCache(cache *Cache, kept []Version, pruned []Version, report *PruneReport) error {
+	sources := make(map[string]bool)
+	for _, version := range pruned {
+		if version.info.Source != "" {
+			sources[version.info.Source] = true
+		}
+	}
+	for _, version := range kept {
+		delete(sources, version.info.Source)
+	}
+	if len(sources) == 0 {
+		return nil
+	}
+	entries, err := cache.List()
+	if err != nil {
+		return errors.Wrapf(err, "failed to list cache %s", cache.Dir)
+	}
+	used := make(map[string]bool)
+	for _, entry := range entries {
+		if !sources[entry.URL] {
+			used[entry.Digests[SHA512]] = true
+		}
+	}
+	for _, entry := range entries {
+		if !sources[entry.URL] {
+			continue
+		}
+		if !report.DryRun {
+			if err := os.Remove(cache.indexFile(entry.URL)); err != nil && !os.IsNotExist(err) {
+				return errors.Wrapf(err, "failed to remove cache entry of %s", RedactURL(entry.URL))
+			}
+		}
+		digest := entry.Digests[SHA512]
+		if used[digest] {
+			continue
+		}
+		used[digest] = true
+		if err := report.remove(cache.blobFile(digest), report.DryRun); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func keepReason(version Version, newest int, policy PrunePolicy) string {
+	if version.Current {
+		return "current"
+	}
+	if pid, ok := launcher.Running(version.Dir); ok {
+		return fmt.Sprintf("launcher %d is running", pid)
+	}
+	if newest < policy.Keep {
+		return fmt.Sprintf("one of the %d most recent", policy.Keep)
+	}
+	if policy.KeepDays > 0 && time.Since(version.Installed) < time.Duration(policy.KeepDays)*24*time.Hour {
+		return fmt.Sprintf("installed within %d days", policy.KeepDays)
+	}
+	return ""
+}
+
+// inUse reports whether the work directory of a pruned version is still needed,
+// because it holds the versions dir, an instance or belongs to a kept version.
+func inUse(workDir string, versionsDir string, kept []Version, pruned Version) bool {
+	if isWithin(workDir, versionsDir) || isWithin(workDir, pruned.info.InstanceDir) {
+		return true
+	}
+	for _, version := range kept {
+		if version.info.WorkDir == workDir || isWithin(workDir, version.info.InstanceDir) {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteText prints the report for humans.
+func (report *PruneReport) WriteText(w io.Writer) {
+	for _, version := range report.Kept {
+		fmt.Fprintf(w, "Keeping %s: %s\n", version.Version, version.Reason)
+	}
+	verb := "Removed"
+	if report.DryRun {
+		verb = "Would remove"
+	}
+	for _, dir := range report.Removed {
+		fmt.Fprintf(w, "%s %s (%s)\n", verb, dir.Dir, humanize.Bytes(uint64(dir.Size)))
+	}
+	for _, dir := range report.Skipped {
+		fmt.Fprintf(w, "Leaving %s: %s\n", dir.Dir, dir.Reason)
+	}
+	if report.DryRun {
+		fmt.Fprintf(w, "Would reclaim %s\n", humanize.Bytes(uint64(report.Reclaimed)))
+	} else {
+		fmt.Fprintf(w, "Reclaimed %s\n", humanize.Bytes(uint64(report.Reclaimed)))
+	}
+}
@@ -0,0 +1,103 @@
+package installer
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/lchudinov/zowe_installer/launcher"
+)
+
+func TestPrune(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need privileges on windows")
+	}
+	tests := []struct {
+		name        string
+		policy      PrunePolicy
+		wantVersion []string
+	}{
+		{"keep 1", PrunePolicy{Keep: 1}, []string{"1.20.0", "1.23.0", "1.25.0"}},
+		{"keep 2", PrunePolicy{Keep: 2}, []string{"1.20.0", "1.23.0", "1.24.0", "1.25.0"}},
+		{"keep days", PrunePolicy{KeepDays: 3}, []string{"1.20.0", "1.23.0", "1.24.0", "1.25.0"}},
+		{"dry run", PrunePolicy{DryRun: true}, []string{"1.9.0", "1.20.0", "1.23.0", "1.24.0", "1.25.0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home, err := ioutil.TempDir("", "prune")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(home)
+			versionsDir := filepath.Join(home, "versions")
+			cache := NewCache(filepath.Join(home, "cache"))
+			tt.policy.CacheDir = cache.Dir
+			ages := map[string]int{"1.9.0": 10, "1.20.0": 8, "1.23.0": 6, "1.24.0": 2, "1.25.0": 1}
+			for version, days := range ages {
+				dir := versionDir(versionsDir, version)
+				workDir := filepath.Join(home, "zowe-"+version)
+				os.MkdirAll(dir, 0755)
+				os.MkdirAll(workDir, 0755)
+				paxFile := filepath.Join(workDir, "zowe.pax")
+				ioutil.WriteFile(paxFile, []byte("pax "+version), 0644)
+				if version != "1.9.0" {
+					ioutil.WriteFile(filepath.Join(workDir, workDirMarker), nil, 0644)
+				}
+				source := "https://zowe.org/zowe-" + version + ".pax"
+				if err := cache.add(source, paxFile, "", ""); err != nil {
+					t.Fatal(err)
+				}
+				info := installInfo{
+					Version:     version,
+					Source:      source,
+					WorkDir:     workDir,
+					InstanceDir: filepath.Join(versionsDir, "instance"),
+					Installed:   time.Now().Add(-time.Duration(days) * 24 * time.Hour),
+				}
+				data, _ := json.Marshal(info)
+				ioutil.WriteFile(filepath.Join(dir, installInfoFile), data, 0644)
+			}
+			SwitchVersion(versionsDir, "1.20.0")
+			pid := strconv.Itoa(os.Getpid())
+			ioutil.WriteFile(filepath.Join(versionDir(versionsDir, "1.23.0"), launcher.PidFile), []byte(pid), 0644)
+			report, err := Prune(versionsDir, tt.policy)
+			if err != nil {
+				t.Fatalf("Prune() error = %v", err)
+			}
+			versions, _ := ListVersions(versionsDir)
+			var got []string
+			for _, version := range versions {
+				got = append(got, version.Version)
+			}
+			if len(got) != len(tt.wantVersion) {
+				t.Fatalf("Prune() left %v, want %v", got, tt.wantVersion)
+			}
+			for i := range got {
+				if got[i] != tt.wantVersion[i] {
+					t.Errorf("Prune() left %v, want %v", got, tt.wantVersion)
+				}
+			}
+			if report.Reclaimed == 0 {
+				t.Errorf("Prune() reclaimed nothing")
+			}
+			if tt.policy.DryRun {
+				return
+			}
+			// the work dir of 1.9.0 wasn't created by the installer
+			if pruned := len(ages) - len(tt.wantVersion); len(report.Removed) != 3*pruned-1 {
+				t.Errorf("Prune() removed %+v, want version and work dirs and cached PAX files", report.Removed)
+			}
+			if len(report.Skipped) != 1 || report.Skipped[0].Dir != filepath.Join(home, "zowe-1.9.0") {
+				t.Errorf("Prune() skipped %+v, want the work dir of 1.9.0", report.Skipped)
+			}
+			if entries, _ := cache.List(); len(entries) != len(tt.wantVersion) {
+				t.Errorf("Prune() left %d cache entries, want %d", len(entries), len(tt.wantVersion))
+			}
+		})
+	}
+}
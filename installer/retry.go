@@ -0,0 +1,75 @@
+package installer
+
+import (
+	"context"
+	"io"
+	"log"
+	"math/rand"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const maxRetryDelay = time.Minute
+
+var jitter = rand.New(rand.NewSource(time.Now().UnixNano()))
+
+// isRetryable tells transient network errors and server side failures apart
+// from errors that a retry can't fix.
+func isRetryable(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= 500 || statusErr.StatusCode == http.StatusTooManyRequests
+	}
+	var idleErr *idleTimeoutError
+	if errors.As(err, &idleErr) {
+		return true
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF)
+}
+
+// retryDelay doubles the retry delay with every attempt and adds jitter so that
+// several installers don't hammer the server in lockstep.
+func (installer *ZoweInstaller) retryDelay(attempt int) time.Duration {
+	delay := installer.RetryDelay << uint(attempt-1)
+	if delay <= 0 || delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	return delay/2 + time.Duration(jitter.Int63n(int64(delay/2)+1))
+}
+
+// retry calls fn until it succeeds, fails with an error that is not retryable,
+// the retries are used up or ctx is done.
+func (installer *ZoweInstaller) retry(ctx context.Context, what string, fn func(ctx context.Context) error) error {
+	attempts := installer.Retries + 1
+	for attempt := 1; ; attempt++ {
+		log.Printf("Downloading %s (attempt %d of %d)", what, attempt, attempts)
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return errors.Wrapf(err, "download of %s timed out after %s", what, installer.Timeout)
+		}
+		if attempt >= attempts || !isRetryable(err) {
+			return err
+		}
+		delay := installer.retryDelay(attempt)
+		log.Printf("Attempt %d failed: %v; retrying in %s", attempt, err, delay.Round(time.Millisecond))
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return errors.Wrapf(err, "download of %s timed out after %s", what, installer.Timeout)
+		}
+	}
+}
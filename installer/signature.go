@@ -0,0 +1,109 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/openpgp"
+)
+
+// SignatureReport is the outcome of checking a detached OpenPGP signature.
+type SignatureReport struct {
+	File        string
+	Signature   string
+	Keyring     string
+	Fingerprint string
+	Identities  []string
+	Err         error
+}
+
+func (r *SignatureReport) Valid() bool {
+	return r.Err == nil
+}
+
+func (r *SignatureReport) String() string {
+	var b strings.Builder
+	if r.Valid() {
+		fmt.Fprintf(&b, "Signature check PASSED for %s\n", r.File)
+	} else {
+		fmt.Fprintf(&b, "Signature check FAILED for %s\n", r.File)
+	}
+	fmt.Fprintf(&b, "  Signature:   %s\n", r.Signature)
+	fmt.Fprintf(&b, "  Keyring:     %s\n", r.Keyring)
+	if r.Fingerprint != "" {
+		fmt.Fprintf(&b, "  Fingerprint: %s\n", r.Fingerprint)
+	}
+	for _, identity := range r.Identities {
+		fmt.Fprintf(&b, "  Signed by:   %s\n", identity)
+	}
+	if r.Err != nil {
+		fmt.Fprintf(&b, "  Error:       %v\n", r.Err)
+	}
+	return b.String()
+}
+
+// VerifySignature checks the armored detached signature of file against the keys
+// in the armored public keyring.
+func VerifySignature(file string, signature string, keyring string) *SignatureReport {
+	report := &SignatureReport{File: file, Signature: signature, Keyring: keyring}
+	keys, err := readKeyring(keyring)
+	if err != nil {
+		report.Err = err
+		return report
+	}
+	signed, err := os.Open(file)
+	if err != nil {
+		report.Err = errors.Wrapf(err, "failed to open %s", file)
+		return report
+	}
+	defer signed.Close()
+	sig, err := os.Open(signature)
+	if err != nil {
+		report.Err = errors.Wrapf(err, "failed to open signature %s", signature)
+		return report
+	}
+	defer sig.Close()
+	signer, err := openpgp.CheckArmoredDetachedSignature(keys, signed, sig)
+	if signer != nil {
+		report.Fingerprint = formatFingerprint(signer.PrimaryKey.Fingerprint[:])
+		for name := range signer.Identities {
+			report.Identities = append(report.Identities, name)
+		}
+		sort.Strings(report.Identities)
+	}
+	if err != nil {
+		report.Err = errors.Wrapf(err, "bad signature")
+	}
+	return report
+}
+
+func readKeyring(keyring string) (openpgp.EntityList, error) {
+	file, err := os.Open(keyring)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open keyring %s", keyring)
+	}
+	defer file.Close()
+	keys, err := openpgp.ReadArmoredKeyRing(file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read keyring %s", keyring)
+	}
+	return keys, nil
+}
+
+// formatFingerprint formats a fingerprint the way gpg prints it, in groups of
+// four hex digits.
+func formatFingerprint(fingerprint []byte) string {
+	hex := fmt.Sprintf("%X", fingerprint)
+	var groups []string
+	for i := 0; i < len(hex); i += 4 {
+		end := i + 4
+		if end > len(hex) {
+			end = len(hex)
+		}
+		groups = append(groups, hex[i:end])
+	}
+	return strings.Join(groups, " ")
+}
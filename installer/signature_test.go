@@ -0,0 +1,82 @@
+package installer
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
+)
+
+func writeSignedFile(t *testing.T, dir string, signer *openpgp.Entity, content string) (string, string) {
+	file := filepath.Join(dir, "zowe.pax")
+	if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var sig bytes.Buffer
+	if err := openpgp.ArmoredDetachSign(&sig, signer, strings.NewReader(content), nil); err != nil {
+		t.Fatal(err)
+	}
+	signature := file + ".asc"
+	if err := ioutil.WriteFile(signature, sig.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return file, signature
+}
+
+func writeKeyring(t *testing.T, file string, entity *openpgp.Entity) {
+	var buf bytes.Buffer
+	w, err := armor.Encode(&buf, openpgp.PublicKeyType, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := entity.Serialize(w); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	if err := ioutil.WriteFile(file, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	dir, err := ioutil.TempDir("", "signature")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	zowe, err := openpgp.NewEntity("Zowe Release", "", "zowe@example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyring := filepath.Join(dir, "keys.asc")
+	writeKeyring(t, keyring, zowe)
+	file, signature := writeSignedFile(t, dir, zowe, "zowe pax content")
+
+	report := VerifySignature(file, signature, keyring)
+	if !report.Valid() {
+		t.Fatalf("VerifySignature() error = %v", report.Err)
+	}
+	want := formatFingerprint(zowe.PrimaryKey.Fingerprint[:])
+	if report.Fingerprint != want {
+		t.Errorf("Fingerprint = %s, want %s", report.Fingerprint, want)
+	}
+	if !strings.Contains(report.String(), "PASSED") {
+		t.Errorf("report doesn't say PASSED:\n%s", report)
+	}
+
+	if err := ioutil.WriteFile(file, []byte("tampered content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	report = VerifySignature(file, signature, keyring)
+	if report.Valid() {
+		t.Errorf("VerifySignature() of tampered file is valid")
+	}
+	if !strings.Contains(report.String(), "FAILED") {
+		t.Errorf("report doesn't say FAILED:\n%s", report)
+	}
+}
@@ -0,0 +1,144 @@
+package installer
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Stdin is the PAX location that makes the installer read the PAX from the
+// standard input.
+const Stdin = "-"
+
+func isRemote(location string) bool {
+	return strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://")
+}
+
+// localPath returns the file system path of a location that is either a plain
+// path or a file:// URL.
+func localPath(location string) (string, error) {
+	if filepath.VolumeName(location) != "" || !strings.Contains(location, "://") {
+		return location, nil
+	}
+	u, err := url.Parse(location)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to parse %s", location)
+	}
+	if u.Scheme != "file" {
+		return "", errors.Errorf("unsupported URL scheme %s in %s", u.Scheme, location)
+	}
+	if u.Host != "" && u.Host != "localhost" {
+		return "", errors.Errorf("remote file URL %s is not supported", location)
+	}
+	return filepath.FromSlash(u.Path), nil
+}
+
+// sourceName returns the file name of the PAX at location.
+func sourceName(location string) (string, error) {
+	if location == Stdin {
+		return "", errors.New("reading the PAX from stdin requires a PAX name")
+	}
+	if isRemote(location) {
+		u, err := url.Parse(location)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to parse PAX URL %s", location)
+		}
+		return path.Base(u.Path), nil
+	}
+	file, err := localPath(location)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Base(file), nil
+}
+
+// readLocation calls fn with the contents of location which can be an HTTP(S)
+// URL, a file:// URL or a local path. HTTP downloads are retried.
+func (installer *ZoweInstaller) readLocation(location string, fn func(r io.Reader) error) error {
+	if isRemote(location) {
+		ctx, cancel := installer.withTimeout(context.Background())
+		defer cancel()
+		return installer.retry(ctx, location, func(ctx context.Context) error {
+			ctx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			resp, err := installer.get(ctx, location)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+			return fn(installer.idleTimeoutReader(resp.Body, cancel))
+		})
+	}
+	file, err := localPath(location)
+	if err != nil {
+		return err
+	}
+	in, err := os.Open(file)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open %s", file)
+	}
+	defer in.Close()
+	return fn(in)
+}
+
+// copyPax copies the PAX from a local file or stdin, verifying its checksum on
+// the way.
+func (installer *ZoweInstaller) copyPax() error {
+	var in io.Reader = os.Stdin
+	total := int64(-1)
+	if installer.paxURL != Stdin {
+		file, err := localPath(installer.paxURL)
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(file)
+		if err != nil {
+			return errors.Wrapf(err, "failed to open PAX %s", file)
+		}
+		defer f.Close()
+		in = f
+		if fi, err := f.Stat(); err == nil {
+			total = fi.Size()
+		}
+	}
+	partFile := installer.paxFileName + partSuffix
+	out, err := os.Create(partFile)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create file for pax")
+	}
+	defer out.Close()
+	var writer io.Writer = out
+	h := installer.newHash()
+	if h != nil {
+		writer = io.MultiWriter(out, h)
+	}
+	progress := installer.startProgress(StageDownload, 0, total)
+	if _, err := io.Copy(writer, io.TeeReader(in, progress)); err != nil {
+		return progress.finish(errors.Wrapf(err, "failed to copy PAX"))
+	}
+	if err := out.Close(); err != nil {
+		return progress.finish(errors.Wrapf(err, "failed to write %s", partFile))
+	}
+	return progress.finish(installer.completePax(partFile, h))
+}
+
+// fetchFile saves a small file such as a signature from location to file.
+func (installer *ZoweInstaller) fetchFile(location string, file string) error {
+	return installer.readLocation(location, func(r io.Reader) error {
+		out, err := os.Create(file)
+		if err != nil {
+			return errors.Wrapf(err, "failed to create %s", file)
+		}
+		if _, err := io.Copy(out, r); err != nil {
+			out.Close()
+			return errors.Wrapf(err, "failed to read %s", location)
+		}
+		return out.Close()
+	})
+}
@@ -0,0 +1,32 @@
+package installer
+
+import "testing"
+
+func Test_sourceName(t *testing.T) {
+	tests := []struct {
+		name     string
+		location string
+		want     string
+		wantErr  bool
+	}{
+		{"http", "https://zowe.jfrog.io/zowe/1.25.0/zowe-1.25.0.pax?download=1", "zowe-1.25.0.pax", false},
+		{"file URL", "file:///var/tmp/zowe-1.25.0.pax", "zowe-1.25.0.pax", false},
+		{"localhost file URL", "file://localhost/var/tmp/zowe-1.25.0.pax", "zowe-1.25.0.pax", false},
+		{"absolute path", "/var/tmp/zowe-1.25.0.pax", "zowe-1.25.0.pax", false},
+		{"relative path", "zowe-1.25.0.pax", "zowe-1.25.0.pax", false},
+		{"stdin", Stdin, "", true},
+		{"remote file URL", "file://host/zowe-1.25.0.pax", "", true},
+		{"unsupported scheme", "ftp://host/zowe-1.25.0.pax", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := sourceName(tt.location)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("sourceName() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("sourceName() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
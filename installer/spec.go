@@ -0,0 +1,233 @@
+package installer
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Spec is a declarative install configuration. It is read from a YAML or JSON
+// file by LoadSpec and applied to the options with ApplySpec.
+type Spec struct {
+	Source        string            `yaml:"source,omitempty" json:"source,omitempty"`
+	Checksum      string            `yaml:"checksum,omitempty" json:"checksum,omitempty"`
+	VerifySidecar bool              `yaml:"verifySidecar,omitempty" json:"verifySidecar,omitempty"`
+	Keyring       string            `yaml:"keyring,omitempty" json:"keyring,omitempty"`
+	SignatureURL  string            `yaml:"signatureUrl,omitempty" json:"signatureUrl,omitempty"`
+	Mirrors       []string          `yaml:"mirrors,omitempty" json:"mirrors,omitempty"`
+	MirrorsFile   string            `yaml:"mirrorsFile,omitempty" json:"mirrorsFile,omitempty"`
+	PaxName       string            `yaml:"paxName,omitempty" json:"paxName,omitempty"`
+	WorkDir       string            `yaml:"workDir,omitempty" json:"workDir,omitempty"`
+	RootDir       string            `yaml:"rootDir,omitempty" json:"rootDir,omitempty"`
+	VersionsDir   string            `yaml:"versionsDir,omitempty" json:"versionsDir,omitempty"`
+	InstanceDir   string            `yaml:"instanceDir,omitempty" json:"instanceDir,omitempty"`
+	LogDir        string            `yaml:"logDir,omitempty" json:"logDir,omitempty"`
+	User          string            `yaml:"user,omitempty" json:"user,omitempty"`
+	Group         string            `yaml:"group,omitempty" json:"group,omitempty"`
+	Hooks         string            `yaml:"hooks,omitempty" json:"hooks,omitempty"`
+	Extensions    []string          `yaml:"extensions,omitempty" json:"extensions,omitempty"`
+	InstanceEnv   map[string]string `yaml:"instanceEnv,omitempty" json:"instanceEnv,omitempty"`
+}
+
+var envName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// LoadSpec reads a spec from a YAML or JSON file. Unknown keys are an error.
+// Relative paths, of a local source, the directories and the files the spec
+// refers to, are resolved against the directory of the spec.
+func LoadSpec(file string) (*Spec, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read install spec")
+	}
+	var spec Spec
+	if err := yaml.UnmarshalStrict(data, &spec); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse install spec %s", file)
+	}
+	dir := filepath.Dir(file)
+	resolve := func(path string) string {
+		if path == "" || path == Stdin || isRemote(path) || strings.Contains(path, "://") || filepath.IsAbs(path) {
+			return path
+		}
+		return filepath.Join(dir, path)
+	}
+	spec.Source = resolve(spec.Source)
+	spec.WorkDir = resolve(spec.WorkDir)
+	spec.RootDir = resolve(spec.RootDir)
+	spec.VersionsDir = resolve(spec.VersionsDir)
+	spec.InstanceDir = resolve(spec.InstanceDir)
+	spec.LogDir = resolve(spec.LogDir)
+	spec.Keyring = resolve(spec.Keyring)
+	spec.MirrorsFile = resolve(spec.MirrorsFile)
+	spec.Hooks = resolve(spec.Hooks)
+	for i, extension := range spec.Extensions {
+		spec.Extensions[i] = resolve(extension)
+	}
+	if err := spec.Validate(); err != nil {
+		return nil, errors.Wrapf(err, "invalid install spec %s", file)
+	}
+	return &spec, nil
+}
+
+// Validate checks the values of spec that can be checked without touching the
+// file system.
+func (spec *Spec) Validate() error {
+	if spec.Checksum != "" {
+		if _, err := ParseChecksum(spec.Checksum); err != nil {
+			return err
+		}
+	}
+	if spec.RootDir != "" && spec.VersionsDir != "" {
+		return errors.New("rootDir can't be combined with versionsDir")
+	}
+	for _, mirror := range spec.Mirrors {
+		if !isRemote(mirror) {
+			return errors.Errorf("mirror %s is not an HTTP(S) URL", RedactURL(mirror))
+		}
+	}
+	for _, extension := range spec.Extensions {
+		if extension == "" {
+			return errors.New("empty extension")
+		}
+	}
+	for name, value := range spec.InstanceEnv {
+		if !envName.MatchString(name) {
+			return errors.Errorf("invalid instance.env variable name %q", name)
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return errors.Errorf("value of instance.env variable %s spans several lines", name)
+		}
+	}
+	return nil
+}
+
+// WriteYAML prints spec as YAML.
+func (spec *Spec) WriteYAML(w io.Writer) error {
+	data, err := yaml.Marshal(spec)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// ApplySpec sets the options given in spec, other options are kept.
+func (installer *ZoweInstaller) ApplySpec(spec *Spec) {
+	set := func(option *string, value string) {
+		if value != "" {
+			*option = value
+		}
+	}
+	set(&installer.Checksum, spec.Checksum)
+	if spec.VerifySidecar {
+		installer.VerifySidecar = true
+	}
+	set(&installer.Keyring, spec.Keyring)
+	set(&installer.SignatureURL, spec.SignatureURL)
+	if len(spec.Mirrors) > 0 {
+		installer.Mirrors = spec.Mirrors
+	}
+	set(&installer.MirrorsFile, spec.MirrorsFile)
+	set(&installer.PaxName, spec.PaxName)
+	set(&installer.WorkDir, spec.WorkDir)
+	set(&installer.RootDir, spec.RootDir)
+	set(&installer.VersionsDir, spec.VersionsDir)
+	set(&installer.InstanceDir, spec.InstanceDir)
+	set(&installer.LogDir, spec.LogDir)
+	set(&installer.User, spec.User)
+	set(&installer.Group, spec.Group)
+	set(&installer.HooksFile, spec.Hooks)
+	if len(spec.Extensions) > 0 {
+		installer.Extensions = spec.Extensions
+	}
+	if len(spec.InstanceEnv) > 0 {
+		installer.InstanceEnv = spec.InstanceEnv
+	}
+}
+
+// Spec returns the options as a spec for the PAX at paxURL, with the
+// credentials removed from the URLs.
+func (installer *ZoweInstaller) Spec(paxURL string) *Spec {
+	var mirrors []string
+	for _, mirror := range installer.Mirrors {
+		mirrors = append(mirrors, RedactURL(mirror))
+	}
+	return &Spec{
+		Source:        RedactURL(paxURL),
+		Checksum:      installer.Checksum,
+		VerifySidecar: installer.VerifySidecar,
+		Keyring:       installer.Keyring,
+		SignatureURL:  RedactURL(installer.SignatureURL),
+		Mirrors:       mirrors,
+		MirrorsFile:   installer.MirrorsFile,
+		PaxName:       installer.PaxName,
+		WorkDir:       installer.WorkDir,
+		RootDir:       installer.RootDir,
+		VersionsDir:   installer.VersionsDir,
+		InstanceDir:   installer.InstanceDir,
+		LogDir:        installer.LogDir,
+		User:          installer.User,
+		Group:         installer.Group,
+		Hooks:         installer.HooksFile,
+		Extensions:    installer.Extensions,
+		InstanceEnv:   installer.InstanceEnv,
+	}
+}
+
+var shellSafe = regexp.MustCompile(`^[A-Za-z0-9_./:,@%+=-]*$`)
+
+// shellQuote quotes value for a shell unless it is made of safe characters only.
+func shellQuote(value string) string {
+	if shellSafe.MatchString(value) {
+		return value
+	}
+	return "'" + strings.Replace(value, "'", `'\''`, -1) + "'"
+}
+
+// applyInstanceEnv sets the InstanceEnv variables in the instance.env of dir,
+// replacing existing assignments and appending the others in name order. The
+// values are quoted as the Zowe scripts source the file.
+func (installer *ZoweInstaller) applyInstanceEnv(dir string) error {
+	if len(installer.InstanceEnv) == 0 {
+		return nil
+	}
+	file := filepath.Join(dir, "instance.env")
+	fi, err := os.Stat(file)
+	if err != nil {
+		return errors.Wrapf(err, "failed to find instance.env")
+	}
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read instance.env")
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	done := make(map[string]bool)
+	for i, line := range lines {
+		parts := strings.SplitN(line, "=", 2)
+		name := strings.TrimSpace(parts[0])
+		if value, ok := installer.InstanceEnv[name]; ok && len(parts) == 2 {
+			lines[i] = name + "=" + shellQuote(value)
+			done[name] = true
+		}
+	}
+	var names []string
+	for name := range installer.InstanceEnv {
+		if !done[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		lines = append(lines, name+"="+shellQuote(installer.InstanceEnv[name]))
+	}
+	if err := ioutil.WriteFile(file, []byte(strings.Join(lines, "\n")+"\n"), fi.Mode().Perm()); err != nil {
+		return errors.Wrapf(err, "failed to write %s", file)
+	}
+	return nil
+}
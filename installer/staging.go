@@ -0,0 +1,159 @@
+package installer
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// stagedDir is a target directory whose previous tree is moved aside, so that
+// the Zowe scripts install the new one in place and write its final paths into
+// the files they generate. The previous tree is put back by restoreStaged or
+// dropped by commitStaged once every step succeeded. Link targets are symlinks.
+type stagedDir struct {
+	Target   string `json:"target"`
+	Previous string `json:"previous,omitempty"`
+	Link     bool   `json:"link,omitempty"`
+}
+
+func backupName(dir string) string {
+	return dir + ".bak-" + time.Now().Format("20060102-150405")
+}
+
+// stage moves the previous tree of target aside. It is recorded in the journal
+// first, so that an interrupted install can put it back.
+func (installer *ZoweInstaller) stage(target string) (*stagedDir, error) {
+	if err := installer.unstage(target); err != nil {
+		return nil, err
+	}
+	staged := &stagedDir{Target: target}
+	if fi, err := os.Lstat(target); err == nil {
+		parent, base := filepath.Split(target)
+		staged.Previous = filepath.Join(parent, "."+base+".previous-"+time.Now().Format("20060102-150405"))
+		staged.Link = fi.Mode()&os.ModeSymlink != 0
+	}
+	installer.journal.Staged = append(installer.journal.Staged, staged)
+	if err := installer.journal.save(); err != nil {
+		return nil, err
+	}
+	if staged.Previous == "" {
+		return staged, nil
+	}
+	if err := os.Rename(target, staged.Previous); err != nil {
+		return nil, errors.Wrapf(err, "failed to move %s aside", target)
+	}
+	log.Printf("Moved previous %s aside", target)
+	return staged, nil
+}
+
+// restoreInterrupted puts back the previous trees a killed run left moved
+// aside, whether or not the install is resumed, so that the half-installed ones
+// aren't taken for the existing installation.
+func (installer *ZoweInstaller) restoreInterrupted() error {
+	file := filepath.Join(installer.dir, journalFile)
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil
+	}
+	var saved journal
+	if err := json.Unmarshal(data, &saved); err != nil || len(saved.Staged) == 0 {
+		return nil
+	}
+	saved.file = file
+	for i := len(saved.Staged) - 1; i >= 0; i-- {
+		staged := saved.Staged[i]
+		if err := staged.restore(); err != nil {
+			return errors.Wrapf(err, "failed to restore %s after an interrupted install", staged.Target)
+		}
+		log.Printf("Restored %s after an interrupted install", staged.Target)
+		saved.Staged = saved.Staged[:i]
+		saved.reset(StageInstall)
+		if err := saved.save(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unstage undoes the leftovers of an earlier attempt to stage target.
+func (installer *ZoweInstaller) unstage(target string) error {
+	var kept []*stagedDir
+	for _, staged := range installer.journal.Staged {
+		if staged.Target != target {
+			kept = append(kept, staged)
+		} else if err := staged.restore(); err != nil {
+			return errors.Wrapf(err, "failed to undo earlier staging of %s", target)
+		}
+	}
+	installer.journal.Staged = kept
+	return nil
+}
+
+// restoreStaged puts back the previous trees of all staged directories, in
+// reverse order, and discards the new ones.
+func (installer *ZoweInstaller) restoreStaged() {
+	staged := installer.journal.Staged
+	if len(staged) == 0 {
+		return
+	}
+	for i := len(staged) - 1; i >= 0; i-- {
+		if err := staged[i].restore(); err != nil {
+			log.Printf("failed to restore %s: %v", staged[i].Target, err)
+			continue
+		}
+		if staged[i].Previous != "" {
+			log.Printf("Restored previous %s", staged[i].Target)
+		}
+	}
+	installer.journal.Staged = nil
+	installer.journal.reset(StageInstall)
+	if err := installer.journal.save(); err != nil {
+		log.Printf("failed to save install state: %v", err)
+	}
+}
+
+func (staged *stagedDir) restore() error {
+	if staged.Previous != "" {
+		if _, err := os.Lstat(staged.Previous); err != nil {
+			// interrupted before the previous tree was moved aside
+			return nil
+		}
+	}
+	if err := os.RemoveAll(staged.Target); err != nil {
+		return err
+	}
+	if staged.Previous == "" {
+		return nil
+	}
+	return os.Rename(staged.Previous, staged.Target)
+}
+
+// commitStaged drops the previous trees, or with Backup moves them to
+// timestamped backups, once every step succeeded.
+func (installer *ZoweInstaller) commitStaged() error {
+	for _, staged := range installer.journal.Staged {
+		if staged.Previous == "" {
+			continue
+		}
+		if staged.Link {
+			if err := os.Remove(staged.Previous); err != nil {
+				return errors.Wrapf(err, "failed to remove previous %s", staged.Target)
+			}
+		} else if installer.Backup {
+			backup := backupName(staged.Target)
+			if err := os.Rename(staged.Previous, backup); err != nil {
+				return errors.Wrapf(err, "failed to back up %s", staged.Target)
+			}
+			log.Printf("Moved previous %s to %s", staged.Target, backup)
+		} else if err := os.RemoveAll(staged.Previous); err != nil {
+			return errors.Wrapf(err, "failed to remove previous %s", staged.Target)
+		}
+	}
+	installer.journal.Staged = nil
+	return installer.journal.save()
+}
@@ -0,0 +1,113 @@
+package installer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestZoweInstaller_stage(t *testing.T) {
+	tests := []struct {
+		name       string
+		previous   bool
+		commit     bool
+		backup     bool
+		wantFile   string
+		wantBackup bool
+	}{
+		{"failed", true, false, false, "old", false},
+		{"failed first install", false, false, false, "", false},
+		{"committed", true, true, false, "new", false},
+		{"committed with backup", true, true, true, "new", true},
+		{"committed first install", false, true, false, "new", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home, err := ioutil.TempDir("", "stage")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(home)
+			installer := New()
+			installer.WorkDir = filepath.Join(home, "zowe")
+			installer.Backup = tt.backup
+			installer.resolve(filepath.Join(home, "zowe.pax"))
+			os.MkdirAll(installer.dir, 0755)
+			installer.journal = installer.newJournal()
+			target := installer.rootDir
+			if tt.previous {
+				os.MkdirAll(target, 0755)
+				ioutil.WriteFile(filepath.Join(target, "version"), []byte("old"), 0644)
+			}
+			if _, err := installer.stage(target); err != nil {
+				t.Fatalf("stage() error = %v", err)
+			}
+			if _, err := os.Stat(target); !os.IsNotExist(err) {
+				t.Fatalf("stage() left %s in place", target)
+			}
+			os.MkdirAll(target, 0755)
+			ioutil.WriteFile(filepath.Join(target, "version"), []byte("new"), 0644)
+			if tt.commit {
+				err = installer.commitStaged()
+			} else {
+				installer.restoreStaged()
+			}
+			if err != nil {
+				t.Fatalf("commitStaged() error = %v", err)
+			}
+			data, _ := ioutil.ReadFile(filepath.Join(target, "version"))
+			if string(data) != tt.wantFile {
+				t.Errorf("%s contains %q, want %q", target, data, tt.wantFile)
+			}
+			left, _ := filepath.Glob(filepath.Join(installer.dir, ".root.*"))
+			if len(left) != 0 {
+				t.Errorf("leftovers %v", left)
+			}
+			backups, _ := filepath.Glob(target + ".bak-*")
+			if (len(backups) == 1) != tt.wantBackup {
+				t.Errorf("backups = %v, want backup %v", backups, tt.wantBackup)
+			}
+		})
+	}
+}
+
+func TestZoweInstaller_restoreInterrupted(t *testing.T) {
+	home, err := ioutil.TempDir("", "stage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+	installer := New()
+	installer.WorkDir = filepath.Join(home, "zowe")
+	installer.resolve(filepath.Join(home, "zowe.pax"))
+	os.MkdirAll(installer.dir, 0755)
+	installer.journal = installer.newJournal()
+	target := installer.rootDir
+	os.MkdirAll(target, 0755)
+	ioutil.WriteFile(filepath.Join(target, "version"), []byte("old"), 0644)
+	if _, err := installer.stage(target); err != nil {
+		t.Fatalf("stage() error = %v", err)
+	}
+	// killed while zowe-install.sh was writing the new tree
+	os.MkdirAll(target, 0755)
+	ioutil.WriteFile(filepath.Join(target, "version"), []byte("half"), 0644)
+
+	rerun := New()
+	rerun.WorkDir = installer.WorkDir
+	rerun.resolve(filepath.Join(home, "zowe.pax"))
+	if err := rerun.restoreInterrupted(); err != nil {
+		t.Fatalf("restoreInterrupted() error = %v", err)
+	}
+	data, _ := ioutil.ReadFile(filepath.Join(target, "version"))
+	if string(data) != "old" {
+		t.Errorf("%s contains %q, want %q", target, data, "old")
+	}
+	if left, _ := filepath.Glob(filepath.Join(installer.dir, ".root.*")); len(left) != 0 {
+		t.Errorf("leftovers %v", left)
+	}
+	rerun.Resume = true
+	if j := rerun.loadJournal(); j == nil || len(j.Staged) != 0 {
+		t.Errorf("loadJournal() = %+v, want no staged dirs", j)
+	}
+}
@@ -0,0 +1,272 @@
+package installer
+
+import (
+	"log"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// StepFunc is a part of a Step, it gets the directories of the installation.
+type StepFunc func(env *StepEnv) error
+
+// Step is a named part of the install pipeline. Check is called for all steps
+// before any of them runs. Rollback undoes Run when a later step fails and
+// Options.Rollback is set. Check and Rollback are optional.
+type Step struct {
+	Name     Stage
+	Check    StepFunc
+	Run      StepFunc
+	Rollback StepFunc
+}
+
+// Hook is an extra step run right before or right after another step, which
+// can be a built-in step or another hook.
+type Hook struct {
+	Step
+	Before Stage
+	After  Stage
+}
+
+// StepEnv describes the installation to the steps.
+type StepEnv struct {
+	Step        Stage
+	Source      string
+	PaxFile     string
+	WorkDir     string
+	RootDir     string
+	InstanceDir string
+	LogDir      string
+}
+
+// Environ returns env as environment variables for the hook scripts.
+func (env *StepEnv) Environ() []string {
+	return []string{
+		"ZOWE_INSTALL_STEP=" + string(env.Step),
+		"ZOWE_INSTALL_SOURCE=" + env.Source,
+		"ZOWE_PAX_FILE=" + env.PaxFile,
+		"ZOWE_WORK_DIR=" + env.WorkDir,
+		"ZOWE_ROOT_DIR=" + env.RootDir,
+		"ZOWE_INSTANCE_DIR=" + env.InstanceDir,
+		"ZOWE_LOG_DIR=" + env.LogDir,
+	}
+}
+
+func (installer *ZoweInstaller) stepEnv() *StepEnv {
+	return &StepEnv{
+		Source:      installer.paxURL,
+		PaxFile:     installer.paxFileName,
+		WorkDir:     installer.dir,
+		RootDir:     installer.rootDir,
+		InstanceDir: installer.instanceDir,
+		LogDir:      installer.logDir,
+	}
+}
+
+// builtinSteps are the steps of every installation.
+func (installer *ZoweInstaller) builtinSteps() []Step {
+	return []Step{
+		{
+			Name: StageDownload,
+			Check: func(*StepEnv) error {
+				if _, err := installer.httpClient(); err != nil {
+					return err
+				}
+				return installer.loadCredentials()
+			},
+			Run: func(*StepEnv) error { return installer.DownloadPax() },
+		},
+		{
+			Name: StageVerify,
+			Check: func(*StepEnv) error {
+				if installer.Keyring == "" {
+					return nil
+				}
+				_, err := readKeyring(installer.Keyring)
+				return err
+			},
+			Run: func(*StepEnv) error { return installer.VerifyPaxSignature() },
+		},
+		{
+			Name: StageExtract,
+			Run:  func(*StepEnv) error { return installer.ExtractPax() },
+			Rollback: func(env *StepEnv) error {
+				extracted := installer.extractedDir()
+				if extracted == "" || extracted == env.WorkDir {
+					return nil
+				}
+				return os.RemoveAll(extracted)
+			},
+		},
+		{
+			Name: StageInstall,
+			Check: func(*StepEnv) error {
+				_, err := installer.installUser()
+				return err
+			},
+			Run: func(*StepEnv) error { return installer.InstallPax() },
+		},
+		{
+			Name: StageConfigure,
+			Check: func(*StepEnv) error {
+				if _, err := installer.instanceGroup(); err != nil {
+					return err
+				}
+				return installer.checkInstanceConfig()
+			},
+			Run: func(*StepEnv) error { return installer.InitInstance() },
+		},
+	}
+}
+
+// pipeline returns the built-in steps with the hooks of Options.Hooks and
+// Options.HooksFile placed around them.
+func (installer *ZoweInstaller) pipeline() ([]Step, error) {
+	hooks := installer.Hooks
+	if installer.HooksFile != "" {
+		fileHooks, err := installer.loadHooks(installer.HooksFile)
+		if err != nil {
+			return nil, err
+		}
+		hooks = append(append([]Hook(nil), hooks...), fileHooks...)
+	}
+	builtin := installer.builtinSteps()
+	// prepare has no step, but failures before the pipeline runs are reported
+	// with it
+	reserved := map[Stage]bool{StagePrepare: true}
+	for _, step := range builtin {
+		reserved[step.Name] = true
+	}
+	names := make(map[Stage]bool)
+	for _, hook := range hooks {
+		if hook.Name == "" {
+			return nil, errors.New("hook without a name")
+		}
+		if reserved[hook.Name] {
+			return nil, errors.Errorf("hook %s has the name of a built-in stage", hook.Name)
+		}
+		if names[hook.Name] {
+			return nil, errors.Errorf("duplicate step %s", hook.Name)
+		}
+		names[hook.Name] = true
+		if (hook.Before == "") == (hook.After == "") {
+			return nil, errors.Errorf("hook %s must be either before or after another step", hook.Name)
+		}
+		if hook.Run == nil {
+			return nil, errors.Errorf("hook %s has nothing to run", hook.Name)
+		}
+	}
+	var steps []Step
+	for _, step := range builtin {
+		steps = append(steps, placeHooks(step, hooks)...)
+	}
+	if len(steps) != len(builtin)+len(hooks) {
+		placed := make(map[Stage]bool)
+		for _, step := range steps {
+			placed[step.Name] = true
+		}
+		for _, hook := range hooks {
+			if !placed[hook.Name] {
+				return nil, errors.Errorf("hook %s refers to unknown step %s%s", hook.Name, hook.Before, hook.After)
+			}
+		}
+	}
+	return steps, nil
+}
+
+// placeHooks returns step surrounded by the hooks attached to it, in the order
+// they are listed.
+func placeHooks(step Step, hooks []Hook) []Step {
+	var steps []Step
+	for _, hook := range hooks {
+		if hook.Before == step.Name {
+			steps = append(steps, placeHooks(hook.Step, hooks)...)
+		}
+	}
+	steps = append(steps, step)
+	for _, hook := range hooks {
+		if hook.After == step.Name {
+			steps = append(steps, placeHooks(hook.Step, hooks)...)
+		}
+	}
+	return steps
+}
+
+// checkSteps calls Check of all steps.
+func (installer *ZoweInstaller) checkSteps(steps []Step) error {
+	env := installer.stepEnv()
+	for _, step := range steps {
+		if step.Check == nil {
+			continue
+		}
+		env.Step = step.Name
+		if err := step.Check(env); err != nil {
+			return errors.Wrapf(err, "check of %s failed", step.Name)
+		}
+	}
+	return nil
+}
+
+// runSteps runs the steps in order. With Resume, steps completed by an earlier
+// run are skipped as long as their outputs are still in place. When a step
+// fails, the previous ROOT_DIR and INSTANCE_DIR are restored.
+func (installer *ZoweInstaller) runSteps(steps []Step) error {
+	env := installer.stepEnv()
+	resuming := installer.Resume
+	var done []Step
+	for _, step := range steps {
+		if resuming && installer.journal.completed(step.Name) {
+			err := installer.verifyStage(step.Name)
+			if err == nil {
+				log.Printf("Skipping %s, completed by an earlier run", step.Name)
+				continue
+			}
+			log.Printf("Rerunning %s: %v", step.Name, err)
+		}
+		resuming = false
+		installer.journal.reset(step.Name)
+		env.Step = step.Name
+		if err := step.Run(env); err != nil {
+			var checksumErr *ChecksumError
+			if errors.As(err, &checksumErr) {
+				err = stageError(StageVerify, err)
+			} else {
+				err = stageError(step.Name, err)
+			}
+			installer.restoreStaged()
+			installer.rollback(env, done)
+			return err
+		}
+		done = append(done, step)
+		if err := installer.recordStage(step.Name); err != nil {
+			installer.restoreStaged()
+			installer.rollback(env, done)
+			return stageError(step.Name, errors.Wrapf(err, "failed to record install state"))
+		}
+	}
+	// the previous trees are dropped at the end of the last step
+	return stageError(steps[len(steps)-1].Name, installer.commitStaged())
+}
+
+// rollback undoes the steps run so far in reverse order when Options.Rollback
+// is set. Failures are logged, the error of the failed step is what matters.
+func (installer *ZoweInstaller) rollback(env *StepEnv, done []Step) {
+	if !installer.Rollback || len(done) == 0 {
+		return
+	}
+	for i := len(done) - 1; i >= 0; i-- {
+		step := done[i]
+		if step.Rollback == nil {
+			continue
+		}
+		log.Printf("Rolling back %s", step.Name)
+		env.Step = step.Name
+		if err := step.Rollback(env); err != nil {
+			log.Printf("failed to roll back %s: %v", step.Name, err)
+		}
+	}
+	installer.journal.reset(done[0].Name)
+	if err := installer.journal.save(); err != nil {
+		log.Printf("failed to save install state: %v", err)
+	}
+}
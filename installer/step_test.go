@@ -0,0 +1,178 @@
+package installer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func noop(*StepEnv) error { return nil }
+
+func TestZoweInstaller_pipeline(t *testing.T) {
+	tests := []struct {
+		name    string
+		hooks   []Hook
+		want    []Stage
+		wantErr bool
+	}{
+		{"builtin", nil, []Stage{StageDownload, StageVerify, StageExtract, StageInstall, StageConfigure}, false},
+		{"hooks", []Hook{
+			{Step: Step{Name: "certs", Run: noop}, After: StageInstall},
+			{Step: Step{Name: "cmdb", Run: noop}, After: StageConfigure},
+			{Step: Step{Name: "space", Run: noop}, Before: StageDownload},
+			{Step: Step{Name: "smoke", Run: noop}, After: "cmdb"},
+			{Step: Step{Name: "keys", Run: noop}, After: StageInstall},
+		}, []Stage{"space", StageDownload, StageVerify, StageExtract, StageInstall, "certs", "keys", StageConfigure, "cmdb", "smoke"}, false},
+		{"unknown step", []Hook{{Step: Step{Name: "certs", Run: noop}, After: "unknown"}}, nil, true},
+		{"built-in name", []Hook{{Step: Step{Name: StageInstall, Run: noop}, After: StageConfigure}}, nil, true},
+		{"prepare", []Hook{{Step: Step{Name: StagePrepare, Run: noop}, Before: StageDownload}}, nil, true},
+		{"duplicate", []Hook{{Step: Step{Name: "certs", Run: noop}, After: StageInstall}, {Step: Step{Name: "certs", Run: noop}, After: StageConfigure}}, nil, true},
+		{"before and after", []Hook{{Step: Step{Name: "certs", Run: noop}, Before: StageInstall, After: StageInstall}}, nil, true},
+		{"nothing to run", []Hook{{Step: Step{Name: "certs"}, After: StageInstall}}, nil, true},
+		{"cycle", []Hook{
+			{Step: Step{Name: "a", Run: noop}, After: "b"},
+			{Step: Step{Name: "b", Run: noop}, After: "a"},
+		}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			installer := New()
+			installer.Hooks = tt.hooks
+			steps, err := installer.pipeline()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("pipeline() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var got []Stage
+			for _, step := range steps {
+				got = append(got, step.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("pipeline() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestZoweInstaller_runSteps(t *testing.T) {
+	tests := []struct {
+		name         string
+		rollback     bool
+		unrecorded   bool
+		wantLog      []string
+		wantJournal  []Stage
+		wantStageErr Stage
+	}{
+		{"no rollback", false, false, []string{"run a", "run b", "run c"}, []Stage{"a", "b"}, "c"},
+		{"rollback", true, false, []string{"run a", "run b", "run c", "rollback b", "rollback a"}, nil, "c"},
+		{"rollback of an unrecorded step", true, true, []string{"run a", "run b", "rollback b", "rollback a"}, nil, "b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home, err := ioutil.TempDir("", "steps")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(home)
+			var log []string
+			step := func(name Stage, fail bool) Step {
+				return Step{
+					Name: name,
+					Run: func(env *StepEnv) error {
+						log = append(log, "run "+string(env.Step))
+						if tt.unrecorded && name == "b" {
+							// the install state can't be saved once b is done
+							os.MkdirAll(filepath.Join(home, journalFile+".tmp"), 0755)
+						}
+						if fail {
+							return errors.New("failed")
+						}
+						return nil
+					},
+					Rollback: func(env *StepEnv) error {
+						log = append(log, "rollback "+string(env.Step))
+						return nil
+					},
+				}
+			}
+			installer := New()
+			installer.WorkDir = home
+			installer.Rollback = tt.rollback
+			installer.resolve(filepath.Join(home, "zowe.pax"))
+			installer.journal = installer.newJournal()
+			err = installer.runSteps([]Step{step("a", false), step("b", false), step("c", true)})
+			var stageErr *StageError
+			if !errors.As(err, &stageErr) || stageErr.Stage != tt.wantStageErr {
+				t.Errorf("runSteps() error = %v, want failure of %s", err, tt.wantStageErr)
+			}
+			if !reflect.DeepEqual(log, tt.wantLog) {
+				t.Errorf("runSteps() ran %v, want %v", log, tt.wantLog)
+			}
+			var stages []Stage
+			for _, s := range installer.journal.Stages {
+				stages = append(stages, s.Stage)
+			}
+			if !reflect.DeepEqual(stages, tt.wantJournal) {
+				t.Errorf("journal stages = %v, want %v", stages, tt.wantJournal)
+			}
+		})
+	}
+}
+
+func TestZoweInstaller_loadHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook scripts are shell scripts")
+	}
+	home, err := ioutil.TempDir("", "hooks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+	script := "#!/bin/sh\necho \"$ZOWE_INSTALL_STEP $ZOWE_ROOT_DIR\" > \"$ZOWE_WORK_DIR/hook.out\"\n"
+	os.MkdirAll(filepath.Join(home, "hooks"), 0755)
+	if err := ioutil.WriteFile(filepath.Join(home, "hooks", "certs.sh"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	// the check only passes when run from the directory of the hooks file
+	check := "#!/bin/sh\ntest -f hooks.json\n"
+	if err := ioutil.WriteFile(filepath.Join(home, "hooks", "check.sh"), []byte(check), 0755); err != nil {
+		t.Fatal(err)
+	}
+	config := `{"steps": [{"name": "certs", "after": "install", "run": ["hooks/certs.sh"], "check": ["hooks/check.sh"]}]}`
+	hooksFile := filepath.Join(home, "hooks.json")
+	ioutil.WriteFile(hooksFile, []byte(config), 0644)
+	installer := New()
+	installer.Stdout = ioutil.Discard
+	installer.Progress = QuietReporter{}
+	installer.WorkDir = filepath.Join(home, "work")
+	installer.HooksFile = hooksFile
+	steps, err := installer.pipeline()
+	if err != nil {
+		t.Fatalf("pipeline() error = %v", err)
+	}
+	if len(steps) != 6 || steps[4].Name != "certs" {
+		t.Fatalf("pipeline() = %+v, want certs after install", steps)
+	}
+	installer.resolve(filepath.Join(home, "zowe.pax"))
+	if err := installer.checkSteps(steps); err != nil {
+		t.Errorf("checkSteps() before the work directory exists error = %v", err)
+	}
+	os.MkdirAll(installer.dir, 0755)
+	env := installer.stepEnv()
+	env.Step = "certs"
+	if err := steps[4].Run(env); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	out, _ := ioutil.ReadFile(filepath.Join(installer.dir, "hook.out"))
+	if want := "certs " + installer.rootDir; strings.TrimSpace(string(out)) != want {
+		t.Errorf("hook wrote %q, want %q", out, want)
+	}
+	if err := installer.checkSteps(steps); err == nil {
+		t.Errorf("checkSteps() in the work directory succeeded, want the check to fail")
+	}
+}
@@ -0,0 +1,233 @@
+package installer
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	humanize "github.com/dustin/go-humanize"
+	"github.com/pkg/errors"
+)
+
+const maxListedEntries = 10
+
+// workDirMarker marks a work directory the installer created, only those are
+// removed by Prune.
+const workDirMarker = ".zowe-work-dir"
+
+// ExistingInstallError is returned when installing would overwrite non-empty
+// directories and neither Force nor Backup is set.
+type ExistingInstallError struct {
+	Dirs []string
+}
+
+func (e *ExistingInstallError) Error() string {
+	return fmt.Sprintf("refusing to overwrite non-empty %s, use --force to delete or --backup to move aside", strings.Join(e.Dirs, ", "))
+}
+
+// isWithin reports whether path is dir or lies inside dir.
+func isWithin(dir string, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// targetDirs returns the work directory followed by ROOT_DIR and INSTANCE_DIR.
+func (installer *ZoweInstaller) targetDirs() []string {
+	targets := []string{installer.dir}
+	for _, dir := range []string{installer.rootDir, installer.instanceDir} {
+		if dir != targets[len(targets)-1] && dir != installer.dir {
+			targets = append(targets, dir)
+		}
+	}
+	return targets
+}
+
+// keptFiles survive the cleanup of the work directory: the files of an
+// interrupted download, the install state and ROOT_DIR and INSTANCE_DIR, which
+// are replaced only once their new contents are in place.
+func (installer *ZoweInstaller) keptFiles() []string {
+	partFile := installer.paxFileName + partSuffix
+	state := filepath.Join(installer.dir, journalFile)
+	return []string{partFile, partFile + metaSuffix, filepath.Join(installer.dir, workDirMarker), state, state + ".tmp", installer.rootDir, installer.instanceDir}
+}
+
+// keepsInstance reports whether the INSTANCE_DIR of a versioned install is
+// already configured. Its ROOT_DIR is the current link, so it is kept for the
+// new version rather than replaced and switching back to an older version
+// still finds it.
+func (installer *ZoweInstaller) keepsInstance() bool {
+	return installer.VersionsDir != "" && fileExists(filepath.Join(installer.instanceDir, "instance.env")) == nil
+}
+
+// createWorkDir creates the work directory, marking it when it didn't exist.
+func (installer *ZoweInstaller) createWorkDir() error {
+	_, err := os.Stat(installer.dir)
+	if err == nil {
+		return nil
+	}
+	if !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.MkdirAll(installer.dir, 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(installer.dir, workDirMarker), nil, 0644)
+}
+
+// notCreatedError refuses to delete the contents of a work directory the
+// installer didn't create, which can be any directory passed with -work-dir.
+func notCreatedError(dir string) error {
+	return errors.Errorf("refusing to delete the contents of %s, it wasn't created by the installer, use --backup to move them aside", dir)
+}
+
+// createdWorkDir reports whether the installer created the work directory dir.
+func createdWorkDir(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, workDirMarker))
+	return err == nil
+}
+
+func readDirExcept(dir string, keep []string) ([]os.FileInfo, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var result []os.FileInfo
+	for _, entry := range entries {
+		kept := false
+		for _, k := range keep {
+			if isWithin(filepath.Join(dir, entry.Name()), k) {
+				kept = true
+			}
+		}
+		if !kept {
+			result = append(result, entry)
+		}
+	}
+	return result, nil
+}
+
+// dirSize returns the total size of the files under path.
+func dirSize(path string) int64 {
+	var size int64
+	filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
+
+func printContents(w io.Writer, dir string, entries []os.FileInfo) {
+	fmt.Fprintf(w, "  %s (%d entries, %s)\n", dir, len(entries), humanize.Bytes(uint64(dirSize(dir))))
+	for i, entry := range entries {
+		if i == maxListedEntries {
+			fmt.Fprintf(w, "    ... and %d more\n", len(entries)-maxListedEntries)
+			break
+		}
+		fmt.Fprintf(w, "    %s\n", entry.Name())
+	}
+}
+
+// prepareTargets makes sure that the target directories are empty. Existing
+// contents are deleted with Force or moved to a timestamped backup with Backup,
+// otherwise an *ExistingInstallError is returned. Force only deletes the
+// contents of a work directory the installer created.
+func (installer *ZoweInstaller) prepareTargets() error {
+	keep := installer.keptFiles()
+	var nonEmpty []string
+	for _, dir := range installer.targetDirs() {
+		if dir == installer.instanceDir && installer.keepsInstance() {
+			continue
+		}
+		entries, err := readDirExcept(dir, keep)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read %s", dir)
+		}
+		if len(entries) == 0 {
+			continue
+		}
+		if len(nonEmpty) == 0 {
+			switch {
+			case installer.Backup:
+				fmt.Fprintln(installer.output(), "Moving to backup:")
+			case installer.Force:
+				fmt.Fprintln(installer.output(), "Deleting:")
+			default:
+				fmt.Fprintln(installer.output(), "Would delete:")
+			}
+		}
+		printContents(installer.output(), dir, entries)
+		nonEmpty = append(nonEmpty, dir)
+	}
+	if len(nonEmpty) == 0 {
+		return nil
+	}
+	if !installer.Backup && !installer.Force {
+		return &ExistingInstallError{Dirs: nonEmpty}
+	}
+	// ROOT_DIR and INSTANCE_DIR are moved aside by the install steps and dropped
+	// once every step succeeded, only the work directory is cleared up front.
+	dir := installer.dir
+	if nonEmpty[0] != dir {
+		return nil
+	}
+	if installer.Backup {
+		backup := backupName(dir)
+		if err := backupDir(dir, backup, keep); err != nil {
+			return errors.Wrapf(err, "failed to back up %s", dir)
+		}
+		log.Printf("Moved %s to %s", dir, backup)
+	} else if !createdWorkDir(dir) {
+		return notCreatedError(dir)
+	} else if err := cleanupDir(dir, keep...); err != nil {
+		return errors.Wrapf(err, "failed to cleanup %s", dir)
+	}
+	return nil
+}
+
+// backupDir renames dir to backup and moves the entries holding the keep files
+// back into a new dir.
+func backupDir(dir string, backup string, keep []string) error {
+	if err := os.Rename(dir, backup); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for _, k := range keep {
+		rel, err := filepath.Rel(dir, k)
+		if err != nil || !isWithin(dir, k) || rel == "." {
+			continue
+		}
+		entry := strings.SplitN(rel, string(filepath.Separator), 2)[0]
+		moved := filepath.Join(backup, entry)
+		if _, err := os.Lstat(moved); err == nil {
+			if err := os.Rename(moved, filepath.Join(dir, entry)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// cleanupDir removes everything in dir except the keep files.
+func cleanupDir(dir string, keep ...string) error {
+	entries, err := readDirExcept(dir, keep)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := os.RemoveAll(filepath.Join(dir, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package installer
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestZoweInstaller_prepareTargets(t *testing.T) {
+	tests := []struct {
+		name       string
+		existing   bool
+		created    bool
+		force      bool
+		backup     bool
+		wantErr    bool
+		wantBackup bool
+	}{
+		{"empty", false, true, false, false, false, false},
+		{"existing", true, true, false, false, true, false},
+		{"existing with force", true, true, true, false, false, false},
+		{"existing with backup", true, true, false, true, false, true},
+		{"foreign with force", true, false, true, false, true, false},
+		{"foreign with backup", true, false, false, true, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home, err := ioutil.TempDir("", "target")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(home)
+			var out bytes.Buffer
+			installer := New()
+			installer.Stdout = &out
+			installer.WorkDir = filepath.Join(home, "zowe")
+			installer.InstanceDir = filepath.Join(home, "instance")
+			installer.Force = tt.force
+			installer.Backup = tt.backup
+			if err := installer.resolveDirs("zowe.pax"); err != nil {
+				t.Fatal(err)
+			}
+			installer.paxFileName = filepath.Join(installer.dir, "zowe.pax")
+			partFile := installer.paxFileName + partSuffix
+			os.MkdirAll(installer.dir, 0755)
+			ioutil.WriteFile(partFile, []byte("partial"), 0644)
+			ioutil.WriteFile(filepath.Join(installer.dir, journalFile), []byte("{}"), 0644)
+			if tt.created {
+				ioutil.WriteFile(filepath.Join(installer.dir, workDirMarker), nil, 0644)
+			}
+			if tt.existing {
+				os.MkdirAll(filepath.Join(installer.rootDir, "bin"), 0755)
+				os.MkdirAll(installer.instanceDir, 0755)
+				ioutil.WriteFile(filepath.Join(installer.instanceDir, "instance.env"), nil, 0644)
+				ioutil.WriteFile(filepath.Join(installer.dir, "old.log"), nil, 0644)
+			}
+			err = installer.prepareTargets()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("prepareTargets() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if e, ok := err.(*ExistingInstallError); tt.created && (!ok || len(e.Dirs) != 3) {
+					t.Errorf("prepareTargets() error = %v, want ExistingInstallError for 3 dirs", err)
+				}
+				if _, err := os.Stat(filepath.Join(installer.dir, "old.log")); err != nil {
+					t.Errorf("work dir was cleaned up: %v", err)
+				}
+				if tt.created && !strings.HasPrefix(out.String(), "Would delete:") {
+					t.Errorf("Stdout = %q, want the contents that would be deleted", out.String())
+				}
+				return
+			}
+			if _, err := os.Stat(partFile); err != nil {
+				t.Errorf("partial download was removed: %v", err)
+			}
+			if _, err := os.Stat(filepath.Join(installer.dir, journalFile)); err != nil {
+				t.Errorf("install state was removed: %v", err)
+			}
+			if _, err := os.Stat(filepath.Join(installer.dir, "old.log")); !os.IsNotExist(err) {
+				t.Errorf("work dir was not cleaned up")
+			}
+			if tt.existing {
+				if _, err := os.Stat(filepath.Join(installer.rootDir, "bin")); err != nil {
+					t.Errorf("root dir was removed before it was replaced: %v", err)
+				}
+			}
+			backups, _ := filepath.Glob(installer.dir + ".bak-*")
+			if (len(backups) == 1) != tt.wantBackup {
+				t.Errorf("work dir backups = %v, want backup %v", backups, tt.wantBackup)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package installer
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// CurrentLink is the symlink in a versions directory pointing at the active
+	// version.
+	CurrentLink     = "current"
+	versionPrefix   = "zowe-"
+	installInfoFile = ".zowe-install.json"
+)
+
+// installInfo is written to every ROOT_DIR the installer creates.
+type installInfo struct {
+	Version     string    `json:"version"`
+	Source      string    `json:"source"`
+	WorkDir     string    `json:"workDir"`
+	InstanceDir string    `json:"instanceDir"`
+	Installed   time.Time `json:"installed"`
+}
+
+// Version is a Zowe version installed in a versions directory.
+type Version struct {
+	Version   string    `json:"version"`
+	Dir       string    `json:"dir"`
+	Source    string    `json:"source,omitempty"`
+	Installed time.Time `json:"installed"`
+	Current   bool      `json:"current"`
+	info      installInfo
+}
+
+// paxVersion takes the version from a PAX name like zowe-1.25.0.pax.
+func paxVersion(paxFile string) string {
+	stem := strings.TrimSuffix(paxFile, filepath.Ext(paxFile))
+	return strings.TrimPrefix(stem, versionPrefix)
+}
+
+// versionDir is the ROOT_DIR of version in versionsDir.
+func versionDir(versionsDir string, version string) string {
+	return filepath.Join(versionsDir, versionPrefix+strings.TrimPrefix(version, versionPrefix))
+}
+
+// compareVersions orders versions by their dot separated parts, numerically
+// where both parts are numbers.
+func compareVersions(a string, b string) int {
+	as := strings.Split(a, ".")
+	bs := strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.Atoi(as[i])
+		bn, bErr := strconv.Atoi(bs[i])
+		switch {
+		case aErr == nil && bErr == nil && an != bn:
+			if an < bn {
+				return -1
+			}
+			return 1
+		case (aErr != nil || bErr != nil) && as[i] != bs[i]:
+			return strings.Compare(as[i], bs[i])
+		}
+	}
+	return len(as) - len(bs)
+}
+
+func (installer *ZoweInstaller) writeInstallInfo(rootDir string) error {
+	version := paxVersion(filepath.Base(installer.paxFileName))
+	if installer.manifest != nil {
+		version = installer.manifest.Version
+	}
+	info := installInfo{
+		Version:     version,
+		Source:      installer.paxURL,
+		WorkDir:     installer.dir,
+		InstanceDir: installer.instanceDir,
+		Installed:   time.Now(),
+	}
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(rootDir, installInfoFile), data, 0644)
+}
+
+// activeRootDir is the ROOT_DIR the instance is configured with. For versioned
+// installs that is the current link, so that switching versions applies to it.
+func (installer *ZoweInstaller) activeRootDir() string {
+	if installer.VersionsDir == "" {
+		return installer.rootDir
+	}
+	versionsDir, err := filepath.Abs(installer.VersionsDir)
+	if err != nil {
+		return installer.rootDir
+	}
+	return filepath.Join(versionsDir, CurrentLink)
+}
+
+// linkCurrent points the current link at the new ROOT_DIR. It is staged like
+// the directories, so a failure of a later step points it back.
+func (installer *ZoweInstaller) linkCurrent() error {
+	link := installer.activeRootDir()
+	if _, err := installer.stage(link); err != nil {
+		return err
+	}
+	if err := os.Symlink(filepath.Base(installer.rootDir), link); err != nil {
+		return errors.Wrapf(err, "failed to create link to %s", installer.rootDir)
+	}
+	return nil
+}
+
+// ListVersions returns the versions installed in versionsDir, oldest first.
+func ListVersions(versionsDir string) ([]Version, error) {
+	entries, err := ioutil.ReadDir(versionsDir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read versions dir")
+	}
+	current, _ := os.Readlink(filepath.Join(versionsDir, CurrentLink))
+	var versions []Version
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), versionPrefix) || strings.Contains(entry.Name(), ".bak-") {
+			continue
+		}
+		version := Version{
+			Version:   strings.TrimPrefix(entry.Name(), versionPrefix),
+			Dir:       filepath.Join(versionsDir, entry.Name()),
+			Installed: entry.ModTime(),
+			Current:   filepath.Base(current) == entry.Name(),
+		}
+		if data, err := ioutil.ReadFile(filepath.Join(version.Dir, installInfoFile)); err == nil {
+			var info installInfo
+			if json.Unmarshal(data, &info) == nil {
+				version.Source = info.Source
+				version.Installed = info.Installed
+				version.info = info
+			}
+		}
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return compareVersions(versions[i].Version, versions[j].Version) < 0
+	})
+	return versions, nil
+}
+
+// SwitchVersion atomically points the current link in versionsDir at an
+// installed version.
+func SwitchVersion(versionsDir string, version string) error {
+	versions, err := ListVersions(versionsDir)
+	if err != nil {
+		return err
+	}
+	dir := versionDir(versionsDir, version)
+	installed := false
+	for _, v := range versions {
+		installed = installed || v.Dir == dir
+	}
+	// backups like zowe-1.25.0.bak-20211001-120000 aren't versions
+	if !installed {
+		return errors.Errorf("version %s is not installed in %s", version, versionsDir)
+	}
+	link := filepath.Join(versionsDir, CurrentLink)
+	tmp := link + ".tmp-" + strconv.Itoa(os.Getpid())
+	os.Remove(tmp)
+	if err := os.Symlink(filepath.Base(dir), tmp); err != nil {
+		return errors.Wrapf(err, "failed to create link to %s", dir)
+	}
+	if err := os.Rename(tmp, link); err != nil {
+		os.Remove(tmp)
+		return errors.Wrapf(err, "failed to switch %s", link)
+	}
+	return nil
+}
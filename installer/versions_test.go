@@ -0,0 +1,150 @@
+package installer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func Test_compareVersions(t *testing.T) {
+	tests := []struct {
+		a    string
+		b    string
+		want int
+	}{
+		{"1.25.0", "1.25.0", 0},
+		{"1.9.0", "1.25.0", -1},
+		{"2.0.0", "1.25.0", 1},
+		{"1.25", "1.25.0", -1},
+		{"1.25.0-rc1", "1.25.0-rc2", -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+" "+tt.b, func(t *testing.T) {
+			got := compareVersions(tt.a, tt.b)
+			if (got < 0) != (tt.want < 0) || (got > 0) != (tt.want > 0) {
+				t.Errorf("compareVersions() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_paxVersion(t *testing.T) {
+	tests := []struct {
+		paxFile string
+		want    string
+	}{
+		{"zowe-1.25.0.pax", "1.25.0"},
+		{"custom.pax", "custom"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.paxFile, func(t *testing.T) {
+			if got := paxVersion(tt.paxFile); got != tt.want {
+				t.Errorf("paxVersion() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSwitchVersion(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need privileges on windows")
+	}
+	versionsDir, err := ioutil.TempDir("", "versions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(versionsDir)
+	for _, version := range []string{"1.9.0", "1.25.0"} {
+		os.MkdirAll(versionDir(versionsDir, version), 0755)
+	}
+	ioutil.WriteFile(filepath.Join(versionsDir, "zowe-1.9.0", installInfoFile), []byte(`{"version":"1.9.0","source":"zowe-1.9.0.pax"}`), 0644)
+	os.MkdirAll(versionDir(versionsDir, "1.25.0")+".bak-20211001-120000", 0755)
+	for _, version := range []string{"1.26.0", "1.25.0.bak-20211001-120000"} {
+		if err := SwitchVersion(versionsDir, version); err == nil {
+			t.Errorf("SwitchVersion(%s) succeeded", version)
+		}
+	}
+	for _, version := range []string{"1.25.0", "zowe-1.9.0"} {
+		if err := SwitchVersion(versionsDir, version); err != nil {
+			t.Fatalf("SwitchVersion(%s) error = %v", version, err)
+		}
+	}
+	versions, err := ListVersions(versionsDir)
+	if err != nil {
+		t.Fatalf("ListVersions() error = %v", err)
+	}
+	if len(versions) != 2 || versions[0].Version != "1.9.0" || versions[1].Version != "1.25.0" {
+		t.Fatalf("ListVersions() = %+v", versions)
+	}
+	if !versions[0].Current || versions[1].Current {
+		t.Errorf("ListVersions() current = %v %v, want 1.9.0", versions[0].Current, versions[1].Current)
+	}
+	if versions[0].Source != "zowe-1.9.0.pax" {
+		t.Errorf("ListVersions() source = %q", versions[0].Source)
+	}
+}
+
+func TestZoweInstaller_linkCurrent(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need privileges on windows")
+	}
+	home, err := ioutil.TempDir("", "link")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+	installer := New()
+	installer.WorkDir = filepath.Join(home, "work")
+	installer.VersionsDir = filepath.Join(home, "versions")
+	installer.resolve(filepath.Join(home, "zowe-1.25.0.pax"))
+	os.MkdirAll(installer.dir, 0755)
+	os.MkdirAll(installer.rootDir, 0755)
+	os.MkdirAll(versionDir(installer.VersionsDir, "1.9.0"), 0755)
+	SwitchVersion(installer.VersionsDir, "1.9.0")
+	installer.journal = installer.newJournal()
+	if err := installer.linkCurrent(); err != nil {
+		t.Fatalf("linkCurrent() error = %v", err)
+	}
+	link := filepath.Join(installer.VersionsDir, CurrentLink)
+	if dest, _ := os.Readlink(link); dest != "zowe-1.25.0" {
+		t.Errorf("current = %s, want zowe-1.25.0", dest)
+	}
+	installer.restoreStaged()
+	if dest, _ := os.Readlink(link); dest != "zowe-1.9.0" {
+		t.Errorf("current = %s after restore, want zowe-1.9.0", dest)
+	}
+}
+
+func TestZoweInstaller_keepsInstance(t *testing.T) {
+	home, err := ioutil.TempDir("", "instance")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+	installer := New()
+	installer.Stdout = ioutil.Discard
+	installer.WorkDir = filepath.Join(home, "work")
+	installer.VersionsDir = filepath.Join(home, "versions")
+	installer.InstanceEnv = map[string]string{"ZOWE_PREFIX": "ZWE2"}
+	installer.resolve(filepath.Join(home, "zowe-1.25.0.pax"))
+	os.MkdirAll(installer.instanceDir, 0755)
+	env := filepath.Join(installer.instanceDir, "instance.env")
+	ioutil.WriteFile(env, []byte("ZOWE_PREFIX=ZWE1\n"), 0644)
+	if err := installer.prepareTargets(); err != nil {
+		t.Fatalf("prepareTargets() error = %v", err)
+	}
+	installer.journal = installer.newJournal()
+	// the new ROOT_DIR has no zowe-configure-instance.sh to run
+	if err := installer.InitInstance(); err != nil {
+		t.Fatalf("InitInstance() error = %v", err)
+	}
+	if len(installer.journal.Staged) != 0 {
+		t.Errorf("InitInstance() staged %+v, want the instance kept", installer.journal.Staged[0])
+	}
+	if data, _ := ioutil.ReadFile(env); !strings.Contains(string(data), "ZOWE_PREFIX=ZWE2") {
+		t.Errorf("instance.env = %q, want the override applied", data)
+	}
+}
@@ -71,6 +71,7 @@ func (launcher *Launcher) Run(instanceDir string, haInstanceId string) error {
 	if err := launcher.findRootDir(); err != nil {
 		return errors.Wrapf(err, "failed to find ROOT_DIR")
 	}
+	launcher.writePidFile()
 	launcher.env = launcher.makeEnvironment()
 	if err := launcher.prepareInstance(); err != nil {
 		return errors.Wrapf(err, "failed to prepare instance")
@@ -89,6 +90,7 @@ func (launcher *Launcher) Run(instanceDir string, haInstanceId string) error {
 
 func (launcher *Launcher) Wait() {
 	launcher.wg.Wait()
+	launcher.removePidFile()
 	launcher.Printf("components stopped")
 }
 
@@ -106,6 +108,14 @@ func (launcher *Launcher) findRootDir() error {
 	if _, err := os.Stat(rootDir); err != nil {
 		return errors.Wrapf(err, "failed to find ROOT_DIR %s", rootDir)
 	}
+	// ROOT_DIR can be the current link of versioned installs, resolve it once so
+	// that the pid file and the components stay with the version started even
+	// when the link is switched.
+	resolved, err := filepath.EvalSymlinks(rootDir)
+	if err != nil {
+		return errors.Wrapf(err, "failed to resolve ROOT_DIR %s", rootDir)
+	}
+	rootDir = resolved
 	launcher.rootDir = rootDir
 	launcher.Printf("ROOT_DIR = %s\n", rootDir)
 	return nil
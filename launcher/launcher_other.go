@@ -8,6 +8,11 @@ func kill(pid int) error {
 	return syscall.Kill(-pid, syscall.SIGTERM)
 }
 
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || err == syscall.EPERM
+}
+
 func getSysProcAttr() *syscall.SysProcAttr {
 	var attr syscall.SysProcAttr
 	attr.Setpgid = true
@@ -1,11 +1,23 @@
 package launcher
 
-import "syscall"
+import (
+	"os"
+	"syscall"
+)
 
 func kill(pid int) error {
 	return nil
 }
 
+func processAlive(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	process.Release()
+	return true
+}
+
 func getSysProcAttr() *syscall.SysProcAttr {
 	var attr syscall.SysProcAttr
 	attr.CreationFlags = syscall.CREATE_NEW_PROCESS_GROUP
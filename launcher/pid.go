@@ -0,0 +1,37 @@
+package launcher
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// PidFile is written to the ROOT_DIR a launcher runs Zowe from, so that tools
+// like zowe_install prune can tell the version is in use.
+const PidFile = ".zowe-launcher.pid"
+
+func (launcher *Launcher) writePidFile() {
+	file := filepath.Join(launcher.rootDir, PidFile)
+	if err := ioutil.WriteFile(file, []byte(strconv.Itoa(os.Getpid())), 0644); err != nil {
+		launcher.Printf("failed to write pid file %s: %v", file, err)
+	}
+}
+
+func (launcher *Launcher) removePidFile() {
+	os.Remove(filepath.Join(launcher.rootDir, PidFile))
+}
+
+// Running returns the pid of a launcher running Zowe from rootDir, if any.
+func Running(rootDir string) (int, bool) {
+	data, err := ioutil.ReadFile(filepath.Join(rootDir, PidFile))
+	if err != nil {
+		return 0, false
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return 0, false
+	}
+	return pid, processAlive(pid)
+}
@@ -0,0 +1,160 @@
+package pax
+
+import (
+	"io"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ExtractFunc is called before an entry is extracted. Returning false skips the
+// entry, returning an error stops the extraction.
+type ExtractFunc func(entry *Entry) (bool, error)
+
+type dirAttrs struct {
+	path    string
+	mode    os.FileMode
+	modTime time.Time
+}
+
+// Extract unpacks the archive read from r into dir. If fn is not nil it is called
+// for every entry to report progress and to filter entries. Entries with absolute
+// names, names or link targets outside dir fail with *UnsafePathError. Entries
+// of TypeOther are logged and skipped.
+func Extract(r io.Reader, dir string, fn ExtractFunc) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return errors.Wrapf(err, "failed to create directory %s", dir)
+	}
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get absolute path of %s", dir)
+	}
+	if root, err = filepath.EvalSymlinks(root); err != nil {
+		return errors.Wrapf(err, "failed to resolve %s", dir)
+	}
+	var dirs []dirAttrs
+	err = Walk(r, func(entry *Entry, r io.Reader) error {
+		name, err := checkEntry(entry)
+		if err != nil {
+			return err
+		}
+		if fn != nil {
+			extract, err := fn(entry)
+			if err != nil {
+				return err
+			}
+			if !extract {
+				return nil
+			}
+		}
+		target := filepath.Join(root, filepath.FromSlash(name))
+		if entry.Type == TypeDir {
+			err = checkResolved(root, target, entry)
+		} else {
+			err = checkResolved(root, filepath.Dir(target), entry)
+		}
+		if err != nil {
+			return err
+		}
+		switch entry.Type {
+		case TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return errors.Wrapf(err, "failed to create directory %s", entry.Name)
+			}
+			// directory modes and times are restored once all their contents are written
+			dirs = append(dirs, dirAttrs{target, entry.Mode, entry.ModTime})
+		case TypeFile:
+			if err := extractFile(target, entry, r); err != nil {
+				return errors.Wrapf(err, "failed to extract file %s", entry.Name)
+			}
+		case TypeSymlink:
+			if err := prepareTarget(target); err != nil {
+				return errors.Wrapf(err, "failed to extract symlink %s", entry.Name)
+			}
+			// not filepath.Join, which would clean a .. after a symlink lexically
+			dest := filepath.Dir(target) + string(filepath.Separator) + filepath.FromSlash(entry.Linkname)
+			if err := checkResolved(root, dest, entry); err != nil {
+				return err
+			}
+			if err := os.Symlink(entry.Linkname, target); err != nil {
+				return errors.Wrapf(err, "failed to extract symlink %s", entry.Name)
+			}
+		case TypeLink:
+			if err := prepareTarget(target); err != nil {
+				return errors.Wrapf(err, "failed to extract link %s", entry.Name)
+			}
+			source := filepath.Join(root, filepath.FromSlash(path.Clean(entry.Linkname)))
+			if err := checkResolved(root, filepath.Dir(source), entry); err != nil {
+				return err
+			}
+			// os.Link links a symlink itself, its target has to stay within
+			// root from the new location too
+			if fi, err := os.Lstat(source); err == nil && fi.Mode()&os.ModeSymlink != 0 {
+				linkname, err := os.Readlink(source)
+				if err != nil {
+					return errors.Wrapf(err, "failed to extract link %s", entry.Name)
+				}
+				if err := checkResolved(root, filepath.Dir(target)+string(filepath.Separator)+linkname, entry); err != nil {
+					return err
+				}
+			}
+			if err := os.Link(source, target); err != nil {
+				return errors.Wrapf(err, "failed to extract link %s", entry.Name)
+			}
+		default:
+			log.Printf("Skipping special entry %s", entry.Name)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for i := len(dirs) - 1; i >= 0; i-- {
+		d := dirs[i]
+		if err := os.Chmod(d.path, d.mode); err != nil {
+			return errors.Wrapf(err, "failed to set mode of directory %s", d.path)
+		}
+		if err := os.Chtimes(d.path, d.modTime, d.modTime); err != nil {
+			return errors.Wrapf(err, "failed to set modification time of directory %s", d.path)
+		}
+	}
+	return nil
+}
+
+// prepareTarget creates the parent directory of target and removes a file left
+// at target by an earlier entry with the same name.
+func prepareTarget(target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	if fi, err := os.Lstat(target); err == nil && !fi.IsDir() {
+		return os.Remove(target)
+	}
+	return nil
+}
+
+func extractFile(target string, entry *Entry, r io.Reader) error {
+	if err := prepareTarget(target); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, r); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	// chmod rather than the create mode so that the umask doesn't drop bits
+	if err := os.Chmod(target, entry.Mode); err != nil {
+		return err
+	}
+	return os.Chtimes(target, entry.ModTime, entry.ModTime)
+}
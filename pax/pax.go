@@ -0,0 +1,86 @@
+// Package pax reads and extracts pax and ustar archives such as Zowe convenience builds.
+package pax
+
+import (
+	"archive/tar"
+	"io"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type EntryType int
+
+const (
+	TypeFile EntryType = iota + 1
+	TypeDir
+	TypeSymlink
+	TypeLink
+	TypeOther
+)
+
+var stringTypes []string = []string{"file", "dir", "symlink", "link", "other"}
+
+func (t EntryType) String() string {
+	if t < TypeFile || t > TypeOther {
+		return "unknown"
+	}
+	return stringTypes[t-1]
+}
+
+// Entry describes a single member of an archive. Extended pax headers and long
+// names are already applied to it.
+type Entry struct {
+	Name     string
+	Linkname string
+	Type     EntryType
+	Mode     os.FileMode
+	Size     int64
+	ModTime  time.Time
+}
+
+// WalkFunc is called for every entry of an archive. For regular files r yields
+// the entry contents until WalkFunc returns.
+type WalkFunc func(entry *Entry, r io.Reader) error
+
+// Walk reads the archive from r and calls fn for each entry in archive order.
+func Walk(r io.Reader, fn WalkFunc) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to read archive header")
+		}
+		entry := newEntry(hdr)
+		if err := fn(entry, tr); err != nil {
+			return err
+		}
+	}
+}
+
+func newEntry(hdr *tar.Header) *Entry {
+	entry := Entry{
+		Name:     hdr.Name,
+		Linkname: hdr.Linkname,
+		Mode:     hdr.FileInfo().Mode() &^ os.ModeType,
+		Size:     hdr.Size,
+		ModTime:  hdr.ModTime,
+	}
+	switch hdr.Typeflag {
+	case tar.TypeReg, tar.TypeCont:
+		entry.Type = TypeFile
+	case tar.TypeDir:
+		entry.Type = TypeDir
+	case tar.TypeSymlink:
+		entry.Type = TypeSymlink
+	case tar.TypeLink:
+		entry.Type = TypeLink
+	default:
+		entry.Type = TypeOther
+	}
+	return &entry
+}
@@ -0,0 +1,263 @@
+package pax
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testEntry struct {
+	hdr  tar.Header
+	body string
+}
+
+func makeArchive(t *testing.T, entries []testEntry) *bytes.Buffer {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, e := range entries {
+		hdr := e.hdr
+		hdr.Size = int64(len(e.body))
+		if hdr.ModTime.IsZero() {
+			hdr.ModTime = time.Unix(1600000000, 0)
+		}
+		if err := tw.WriteHeader(&hdr); err != nil {
+			t.Fatalf("failed to write header %s: %v", hdr.Name, err)
+		}
+		if _, err := tw.Write([]byte(e.body)); err != nil {
+			t.Fatalf("failed to write body %s: %v", hdr.Name, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("failed to close archive: %v", err)
+	}
+	return &buf
+}
+
+var longName = "zowe-1.25.0/" + strings.Repeat("very-long-directory-name/", 8) + "file.txt"
+
+var testEntries = []testEntry{
+	{tar.Header{Name: "zowe-1.25.0/", Typeflag: tar.TypeDir, Mode: 0755}, ""},
+	{tar.Header{Name: "zowe-1.25.0/install/zowe-install.sh", Typeflag: tar.TypeReg, Mode: 0750, Format: tar.FormatPAX}, "#!/bin/sh\n"},
+	{tar.Header{Name: "zowe-1.25.0/manifest.json", Typeflag: tar.TypeReg, Mode: 0644, Format: tar.FormatUSTAR}, "{}"},
+	{tar.Header{Name: longName, Typeflag: tar.TypeReg, Mode: 0600, Format: tar.FormatPAX}, "long"},
+	{tar.Header{Name: "zowe-1.25.0/latest", Typeflag: tar.TypeSymlink, Linkname: "manifest.json", Mode: 0777}, ""},
+	{tar.Header{Name: "zowe-1.25.0/manifest.copy", Typeflag: tar.TypeLink, Linkname: "zowe-1.25.0/manifest.json"}, ""},
+}
+
+func TestWalk(t *testing.T) {
+	tests := []struct {
+		name  string
+		typ   EntryType
+		mode  os.FileMode
+		size  int64
+		link  string
+		index int
+	}{
+		{"dir", TypeDir, 0755, 0, "", 0},
+		{"pax file", TypeFile, 0750, 10, "", 1},
+		{"ustar file", TypeFile, 0644, 2, "", 2},
+		{"long name", TypeFile, 0600, 4, "", 3},
+		{"symlink", TypeSymlink, 0777, 0, "manifest.json", 4},
+		{"hardlink", TypeLink, 0, 0, "zowe-1.25.0/manifest.json", 5},
+	}
+	var entries []*Entry
+	err := Walk(makeArchive(t, testEntries), func(entry *Entry, r io.Reader) error {
+		entries = append(entries, entry)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk() error = %v", err)
+	}
+	if len(entries) != len(testEntries) {
+		t.Fatalf("Walk() got %d entries, want %d", len(entries), len(testEntries))
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := entries[tt.index]
+			if got.Name != testEntries[tt.index].hdr.Name {
+				t.Errorf("Name = %s, want %s", got.Name, testEntries[tt.index].hdr.Name)
+			}
+			if got.Type != tt.typ || got.Mode != tt.mode || got.Size != tt.size || got.Linkname != tt.link {
+				t.Errorf("entry = %s %v %d %q, want %s %v %d %q", got.Type, got.Mode, got.Size, got.Linkname, tt.typ, tt.mode, tt.size, tt.link)
+			}
+		})
+	}
+}
+
+func TestExtract(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pax")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	var names []string
+	err = Extract(makeArchive(t, testEntries), dir, func(entry *Entry) (bool, error) {
+		names = append(names, entry.Name)
+		return entry.Name != longName, nil
+	})
+	if err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+	if len(names) != len(testEntries) {
+		t.Errorf("Extract() reported %d entries, want %d", len(names), len(testEntries))
+	}
+	fi, err := os.Stat(filepath.Join(dir, "zowe-1.25.0", "install", "zowe-install.sh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != 0750 {
+		t.Errorf("zowe-install.sh mode = %v, want %v", fi.Mode().Perm(), os.FileMode(0750))
+	}
+	if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(longName))); !os.IsNotExist(err) {
+		t.Errorf("filtered entry was extracted")
+	}
+	link, err := os.Readlink(filepath.Join(dir, "zowe-1.25.0", "latest"))
+	if err != nil || link != "manifest.json" {
+		t.Errorf("latest -> %q, %v, want manifest.json", link, err)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, "zowe-1.25.0", "manifest.copy"))
+	if err != nil || string(data) != "{}" {
+		t.Errorf("manifest.copy = %q, %v, want {}", data, err)
+	}
+}
+
+func TestExtractUnsafe(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []testEntry
+		bad     string
+	}{
+		{
+			"absolute path",
+			[]testEntry{{tar.Header{Name: "/tmp/evil", Typeflag: tar.TypeReg, Mode: 0644}, "x"}},
+			"/tmp/evil",
+		},
+		{
+			"parent escape",
+			[]testEntry{{tar.Header{Name: "zowe/../../evil", Typeflag: tar.TypeReg, Mode: 0644}, "x"}},
+			"zowe/../../evil",
+		},
+		{
+			"absolute symlink",
+			[]testEntry{{tar.Header{Name: "etc", Typeflag: tar.TypeSymlink, Linkname: "/etc"}, ""}},
+			"etc",
+		},
+		{
+			"symlink escape",
+			[]testEntry{{tar.Header{Name: "zowe/up", Typeflag: tar.TypeSymlink, Linkname: "../.."}, ""}},
+			"zowe/up",
+		},
+		{
+			"hardlink escape",
+			[]testEntry{{tar.Header{Name: "passwd", Typeflag: tar.TypeLink, Linkname: "../../etc/passwd"}, ""}},
+			"passwd",
+		},
+		{
+			"write through symlink",
+			[]testEntry{
+				{tar.Header{Name: "a/b", Typeflag: tar.TypeDir, Mode: 0755}, ""},
+				{tar.Header{Name: "a/b/l", Typeflag: tar.TypeSymlink, Linkname: "../.."}, ""},
+				{tar.Header{Name: "s", Typeflag: tar.TypeSymlink, Linkname: "a/b/l/.."}, ""},
+				{tar.Header{Name: "s/evil", Typeflag: tar.TypeReg, Mode: 0644}, "x"},
+			},
+			"s",
+		},
+		{
+			"hardlink to symlink",
+			[]testEntry{
+				{tar.Header{Name: "a/", Typeflag: tar.TypeDir, Mode: 0755}, ""},
+				{tar.Header{Name: "a/s", Typeflag: tar.TypeSymlink, Linkname: "../x"}, ""},
+				{tar.Header{Name: "l", Typeflag: tar.TypeLink, Linkname: "a/s"}, ""},
+			},
+			"l",
+		},
+		{
+			"symlink through symlink",
+			[]testEntry{
+				{tar.Header{Name: "a/l", Typeflag: tar.TypeSymlink, Linkname: ".."}, ""},
+				{tar.Header{Name: "a/up", Typeflag: tar.TypeSymlink, Linkname: "l/.."}, ""},
+			},
+			"a/up",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parent, err := ioutil.TempDir("", "pax")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(parent)
+			dir := filepath.Join(parent, "root")
+			err = Extract(makeArchive(t, tt.entries), dir, nil)
+			unsafeErr, ok := err.(*UnsafePathError)
+			if !ok {
+				t.Fatalf("Extract() error = %v, want *UnsafePathError", err)
+			}
+			if unsafeErr.Name != tt.bad {
+				t.Errorf("UnsafePathError.Name = %s, want %s", unsafeErr.Name, tt.bad)
+			}
+			if _, err := os.Stat(filepath.Join(parent, "evil")); !os.IsNotExist(err) {
+				t.Errorf("file was written outside extraction root")
+			}
+			root, _ := filepath.EvalSymlinks(dir)
+			filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+				if err != nil || info.Mode()&os.ModeSymlink == 0 {
+					return nil
+				}
+				resolved, err := filepath.EvalSymlinks(p)
+				if err != nil {
+					return nil
+				}
+				if rel, err := filepath.Rel(root, resolved); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+					t.Errorf("%s reaches %s outside extraction root", p, resolved)
+				}
+				return nil
+			})
+		})
+	}
+}
+
+func TestEntryType_String(t *testing.T) {
+	tests := []struct {
+		typ  EntryType
+		want string
+	}{
+		{TypeFile, "file"},
+		{TypeOther, "other"},
+		{0, "unknown"},
+		{TypeOther + 1, "unknown"},
+	}
+	for _, tt := range tests {
+		if got := tt.typ.String(); got != tt.want {
+			t.Errorf("EntryType(%d).String() = %s, want %s", int(tt.typ), got, tt.want)
+		}
+	}
+}
+
+func TestExtractOther(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pax")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+	entries := []testEntry{{tar.Header{Name: "zowe/fifo", Typeflag: tar.TypeFifo, Mode: 0644}, ""}}
+	if err := Extract(makeArchive(t, entries), dir, nil); err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+	if _, err := os.Lstat(filepath.Join(dir, "zowe", "fifo")); !os.IsNotExist(err) {
+		t.Errorf("special entry was extracted")
+	}
+	if !strings.Contains(logs.String(), "zowe/fifo") {
+		t.Errorf("skipped special entry wasn't logged: %q", logs.String())
+	}
+}
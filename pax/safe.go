@@ -0,0 +1,84 @@
+package pax
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// UnsafePathError is returned when an entry would be written, or a link would
+// point, outside the extraction root.
+type UnsafePathError struct {
+	Name     string
+	Linkname string
+	Reason   string
+}
+
+func (e *UnsafePathError) Error() string {
+	if e.Linkname != "" {
+		return fmt.Sprintf("unsafe archive entry %s -> %s: %s", e.Name, e.Linkname, e.Reason)
+	}
+	return fmt.Sprintf("unsafe archive entry %s: %s", e.Name, e.Reason)
+}
+
+func isAbs(name string) bool {
+	return path.IsAbs(name) || filepath.IsAbs(name) || filepath.VolumeName(name) != ""
+}
+
+func escapes(name string) bool {
+	return name == ".." || strings.HasPrefix(name, "../")
+}
+
+// checkEntry lexically validates the entry name and link target and returns the
+// cleaned archive-relative name.
+func checkEntry(entry *Entry) (string, error) {
+	if isAbs(entry.Name) {
+		return "", &UnsafePathError{Name: entry.Name, Reason: "absolute path"}
+	}
+	name := path.Clean(entry.Name)
+	if escapes(name) {
+		return "", &UnsafePathError{Name: entry.Name, Reason: "path escapes extraction root"}
+	}
+	switch entry.Type {
+	case TypeSymlink:
+		if isAbs(entry.Linkname) {
+			return "", &UnsafePathError{Name: entry.Name, Linkname: entry.Linkname, Reason: "absolute symlink target"}
+		}
+		if escapes(path.Join(path.Dir(name), entry.Linkname)) {
+			return "", &UnsafePathError{Name: entry.Name, Linkname: entry.Linkname, Reason: "symlink target escapes extraction root"}
+		}
+	case TypeLink:
+		if isAbs(entry.Linkname) || escapes(path.Clean(entry.Linkname)) {
+			return "", &UnsafePathError{Name: entry.Name, Linkname: entry.Linkname, Reason: "hardlink target escapes extraction root"}
+		}
+	}
+	return name, nil
+}
+
+// checkResolved makes sure that p, after resolving the symlinks of its nearest
+// existing ancestor, still lies within root. This catches escapes through
+// symlinks extracted earlier, which a lexical check can't see.
+func checkResolved(root string, p string, entry *Entry) error {
+	existing := p
+	for {
+		if _, err := os.Lstat(existing); err == nil {
+			break
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			break
+		}
+		existing = parent
+	}
+	resolved, err := filepath.EvalSymlinks(existing)
+	if err != nil {
+		return &UnsafePathError{Name: entry.Name, Linkname: entry.Linkname, Reason: fmt.Sprintf("failed to resolve %s: %v", existing, err)}
+	}
+	rel, err := filepath.Rel(root, resolved)
+	if err != nil || escapes(filepath.ToSlash(rel)) {
+		return &UnsafePathError{Name: entry.Name, Linkname: entry.Linkname, Reason: "path resolves outside extraction root"}
+	}
+	return nil
+}